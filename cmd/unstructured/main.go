@@ -0,0 +1,640 @@
+// Command unstructured reads and edits JSON and YAML documents from the shell,
+// using json pointers as described in RFC 6901, with the same extensions as
+// the unstructured library:
+//
+//	unstructured get manifest.yml /instance_groups/name=web/instances
+//	unstructured set -i manifest.yml /instance_groups/name=web/instances 3
+//	unstructured delete manifest.yml /instance_groups/name=web/jobs/name=debug?
+//	unstructured keys manifest.yml /instance_groups/0
+//	unstructured type manifest.yml /update
+//	unstructured diff old.yml new.yml
+//	unstructured patch -i manifest.yml scale-up.yml
+//
+// Files are parsed as JSON or YAML according to their extension, or by
+// looking at their contents if the extension doesn't say. A file of `-`
+// means standard input. Documents are written in the format they were read
+// in, unless `-o` says otherwise. The set, delete and patch commands write
+// the new document to standard output, or back to the file with `-i`. Either
+// way, the whole document is written out afresh, so comments, key order and
+// formatting are lost. Since documents are held as float64s, these commands
+// refuse to edit documents with integers too big to hold exactly, and YAML
+// files with more than one document are always refused.
+//
+// Like diff(1), the diff command exits with status 0 if the documents are
+// the same, 1 if they differ, and 2 if something went wrong, so that it can
+// be used to check documents in CI.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/totherme/unstructured"
+	"github.com/totherme/unstructured/opsfile"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+const usage = `Usage:
+  unstructured get [-o json|yaml] <file> <pointer>
+  unstructured set [-i] [-s] [-o json|yaml] <file> <pointer> <value>
+  unstructured delete [-i] [-o json|yaml] <file> <pointer>
+  unstructured keys <file> [<pointer>]
+  unstructured type <file> [<pointer>]
+  unstructured diff [-patch] [-o json|yaml] [-ignore <pattern>]... [-unordered <pattern>]... <from> <to>
+  unstructured patch [-i] [-t json|merge|ops] [-o json|yaml] <file> <patch>
+
+A <file> of - means standard input. The <value> given to set is parsed as
+YAML, so 3 is a number and '{name: web}' is an object. Use -s to set it as a
+string instead.
+
+set, delete and patch write the whole document out again, so comments, key
+order and formatting are lost, and -i replaces the file with the result.
+They refuse documents containing integers which can't be held exactly as
+64-bit floats, such as 9007199254740993, rather than changing them. A YAML
+file may only contain one document.
+
+diff lists the differences between two documents, or prints them as a JSON
+patch (RFC 6902) with -patch. It exits with status 1 if there are any
+differences, and 2 if something goes wrong. Patterns may use * and ** to
+match any one or more segments.
+
+patch applies a JSON patch, a JSON merge patch (RFC 7396) or a BOSH ops file
+to a document. The kind of patch is worked out from its contents, unless -t
+says otherwise.
+`
+
+const (
+	formatJSON = "json"
+	formatYAML = "yaml"
+)
+
+const (
+	patchJSON  = "json"
+	patchMerge = "merge"
+	patchOps   = "ops"
+)
+
+var (
+	// errUsage means the command line was wrong, and the usage should be
+	// shown.
+	errUsage = errors.New("invalid usage")
+	// errDifferent means diff found differences, which it has already
+	// described.
+	errDifferent = errors.New("the documents differ")
+)
+
+type command struct {
+	stdin  io.Reader
+	stdout io.Writer
+	flags  *flag.FlagSet
+
+	inPlace   bool
+	editing   bool
+	asString  bool
+	outFormat string
+	asPatch   bool
+	patchType string
+	ignored   patterns
+	unordered patterns
+
+	// errStatus is the exit status for errors other than usage errors.
+	errStatus int
+}
+
+// patterns collects the values of a flag which may be given several times.
+type patterns []string
+
+func (p *patterns) String() string {
+	return strings.Join(*p, ",")
+}
+
+func (p *patterns) Set(pattern string) error {
+	*p = append(*p, pattern)
+	return nil
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	c := &command{stdin: stdin, stdout: stdout, flags: flag.NewFlagSet(args[0], flag.ContinueOnError), errStatus: 1}
+	c.flags.SetOutput(stderr)
+	c.flags.Usage = func() { fmt.Fprint(stderr, usage) }
+	c.flags.StringVar(&c.outFormat, "o", "", "write documents as `json` or yaml")
+
+	var subcommand func([]string) error
+	switch args[0] {
+	case "get":
+		subcommand = c.get
+	case "set":
+		c.editing = true
+		c.flags.BoolVar(&c.inPlace, "i", false, "write the result back to the file")
+		c.flags.BoolVar(&c.asString, "s", false, "set the value as a string, rather than parsing it")
+		subcommand = c.set
+	case "delete":
+		c.editing = true
+		c.flags.BoolVar(&c.inPlace, "i", false, "write the result back to the file")
+		subcommand = c.delete
+	case "keys":
+		subcommand = c.keys
+	case "type":
+		subcommand = c.typ
+	case "diff":
+		c.flags.BoolVar(&c.asPatch, "patch", false, "print the differences as a JSON patch")
+		c.flags.Var(&c.ignored, "ignore", "ignore differences at pointers matching `pattern`")
+		c.flags.Var(&c.unordered, "unordered", "compare lists at pointers matching `pattern` in any order")
+		c.errStatus = 2
+		subcommand = c.diff
+	case "patch":
+		c.editing = true
+		c.flags.BoolVar(&c.inPlace, "i", false, "write the result back to the file")
+		c.flags.StringVar(&c.patchType, "t", "", "the kind of patch: `json`, merge or ops")
+		subcommand = c.patch
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "unstructured: unknown command '%s'\n%s", args[0], usage)
+		return 2
+	}
+
+	if err := c.flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if c.outFormat != "" && c.outFormat != formatJSON && c.outFormat != formatYAML {
+		fmt.Fprintf(stderr, "unstructured: unknown output format '%s'\n", c.outFormat)
+		return 2
+	}
+	err := subcommand(c.flags.Args())
+	switch {
+	case errors.Is(err, errUsage):
+		fmt.Fprint(stderr, usage)
+		return 2
+	case errors.Is(err, errDifferent):
+		return 1
+	case err != nil:
+		fmt.Fprintf(stderr, "unstructured: %s\n", err)
+		return c.errStatus
+	}
+	return 0
+}
+
+func (c *command) get(args []string) error {
+	if len(args) != 2 {
+		return errUsage
+	}
+	doc, format, err := c.read(args[0])
+	if err != nil {
+		return err
+	}
+	val, err := lookup(doc, args[1])
+	if err != nil {
+		return err
+	}
+	return c.write(c.stdout, val, format)
+}
+
+func (c *command) set(args []string) error {
+	if len(args) != 3 {
+		return errUsage
+	}
+	doc, format, err := c.read(args[0])
+	if err != nil {
+		return err
+	}
+	var val interface{} = args[2]
+	if !c.asString {
+		parsed, err := unstructured.ParseYAML(args[2])
+		if err != nil {
+			return fmt.Errorf("couldn't parse the value: %s", err)
+		}
+		if err := checkNumbers([]byte(args[2]), formatYAML); err != nil {
+			return fmt.Errorf("can't set the value: %s", err)
+		}
+		val = parsed.RawValue()
+	}
+	updated, err := doc.WithPointerSet(args[1], val)
+	if err != nil {
+		return err
+	}
+	return c.output(args[0], updated, format)
+}
+
+func (c *command) delete(args []string) error {
+	if len(args) != 2 {
+		return errUsage
+	}
+	doc, format, err := c.read(args[0])
+	if err != nil {
+		return err
+	}
+	if args[1] == "" {
+		return fmt.Errorf("can't delete the whole document")
+	}
+	updated, err := doc.WithPointerDeleted(args[1])
+	if err != nil {
+		return err
+	}
+	return c.output(args[0], updated, format)
+}
+
+func (c *command) keys(args []string) error {
+	if len(args) != 1 && len(args) != 2 {
+		return errUsage
+	}
+	val, err := c.readAt(args)
+	if err != nil {
+		return err
+	}
+	var keys []string
+	switch {
+	case val.IsOb():
+		keys, _ = val.Keys()
+		sort.Strings(keys)
+	case val.IsList():
+		for i := range val.UnsafeListValue() {
+			keys = append(keys, strconv.Itoa(i))
+		}
+	default:
+		return fmt.Errorf("the value is of type %s, so it has no keys", val.Type())
+	}
+	for _, key := range keys {
+		fmt.Fprintln(c.stdout, key)
+	}
+	return nil
+}
+
+func (c *command) typ(args []string) error {
+	if len(args) != 1 && len(args) != 2 {
+		return errUsage
+	}
+	val, err := c.readAt(args)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(c.stdout, val.Type())
+	return nil
+}
+
+func (c *command) diff(args []string) error {
+	if len(args) != 2 {
+		return errUsage
+	}
+	from, to, format, err := c.readPair(args[0], args[1])
+	if err != nil {
+		return err
+	}
+	var opts []unstructured.DiffOption
+	for _, pattern := range c.ignored {
+		opts = append(opts, unstructured.IgnoreAt(pattern))
+	}
+	for _, pattern := range c.unordered {
+		opts = append(opts, unstructured.UnorderedListsAt(pattern))
+	}
+
+	diffs, err := unstructured.Diff(from, to, opts...)
+	if err != nil {
+		return err
+	}
+	if c.asPatch {
+		patch, err := unstructured.DiffPatch(from, to, opts...)
+		if err != nil {
+			return err
+		}
+		if c.outFormat == "" {
+			format = formatJSON
+		}
+		if err := c.write(c.stdout, patch, format); err != nil {
+			return err
+		}
+	} else {
+		for _, diff := range diffs {
+			fmt.Fprintln(c.stdout, describeDifference(diff))
+		}
+	}
+	if len(diffs) > 0 {
+		return errDifferent
+	}
+	return nil
+}
+
+// describeDifference renders a Difference as a line of diff output, marked
+// with +, - or ~ for added, removed and changed values.
+func describeDifference(diff unstructured.Difference) string {
+	switch diff.Type {
+	case unstructured.Added:
+		return fmt.Sprintf("+ %s: %s", diff.Pointer, compactJSON(diff.To))
+	case unstructured.Removed:
+		return fmt.Sprintf("- %s: %s", diff.Pointer, compactJSON(diff.From))
+	default:
+		return fmt.Sprintf("~ %s: %s -> %s", diff.Pointer, compactJSON(diff.From), compactJSON(diff.To))
+	}
+}
+
+func compactJSON(d unstructured.Data) string {
+	encoded, err := encodeJSON(d.RawValue(), "")
+	if err != nil {
+		return fmt.Sprintf("%+v", d.RawValue())
+	}
+	return strings.TrimSuffix(string(encoded), "\n")
+}
+
+func (c *command) patch(args []string) error {
+	if len(args) != 2 {
+		return errUsage
+	}
+	doc, patch, format, err := c.readPair(args[0], args[1])
+	if err != nil {
+		return err
+	}
+	kind := c.patchType
+	if kind == "" {
+		if kind, err = patchKind(patch); err != nil {
+			return fmt.Errorf("%s, so use -t to say what kind of patch %s is", err, displayName(args[1]))
+		}
+	}
+
+	var patched unstructured.Data
+	switch kind {
+	case patchJSON:
+		patched, err = unstructured.ApplyPatch(doc, patch)
+	case patchMerge:
+		patched = unstructured.ApplyMergePatch(doc, patch)
+	case patchOps:
+		patched, err = opsfile.Apply(doc, patch)
+	default:
+		return fmt.Errorf("unknown kind of patch '%s'", kind)
+	}
+	if err != nil {
+		return err
+	}
+	return c.output(args[0], patched, format)
+}
+
+// patchKind works out what kind of patch `patch` is. A merge patch is an
+// object, and JSON patches and ops files are lists of objects, with `op` and
+// `type` fields respectively.
+func patchKind(patch unstructured.Data) (string, error) {
+	if !patch.IsList() {
+		return patchMerge, nil
+	}
+	ops := patch.UnsafeListValue()
+	if len(ops) == 0 {
+		return patchJSON, nil
+	}
+	if ops[0].IsOb() {
+		switch {
+		case ops[0].HasKey("op"):
+			return patchJSON, nil
+		case ops[0].HasKey("type"):
+			return patchOps, nil
+		}
+	}
+	return "", fmt.Errorf("the first operation has neither an 'op' nor a 'type'")
+}
+
+// readPair reads two files, at most one of which may be standard input, and
+// returns the format of the first.
+func (c *command) readPair(first, second string) (unstructured.Data, unstructured.Data, string, error) {
+	if first == "-" && second == "-" {
+		return unstructured.Data{}, unstructured.Data{}, "", fmt.Errorf("only one of the files can be standard input")
+	}
+	firstDoc, format, err := c.read(first)
+	if err != nil {
+		return unstructured.Data{}, unstructured.Data{}, "", err
+	}
+	secondDoc, _, err := c.read(second)
+	if err != nil {
+		return unstructured.Data{}, unstructured.Data{}, "", err
+	}
+	return firstDoc, secondDoc, format, nil
+}
+
+// readAt reads the file named by args[0], and returns the value at the
+// pointer args[1], or the whole document if there's no pointer.
+func (c *command) readAt(args []string) (unstructured.Data, error) {
+	doc, _, err := c.read(args[0])
+	if err != nil {
+		return unstructured.Data{}, err
+	}
+	if len(args) == 1 {
+		return doc, nil
+	}
+	return lookup(doc, args[1])
+}
+
+func lookup(doc unstructured.Data, p string) (unstructured.Data, error) {
+	found, err := doc.HasPointer(p)
+	if err != nil {
+		return unstructured.Data{}, err
+	}
+	if !found {
+		return unstructured.Data{}, fmt.Errorf("there's nothing at pointer '%s'", p)
+	}
+	return doc.GetByPointer(p)
+}
+
+// read parses the file at `path`, or standard input if `path` is `-`, and
+// returns the format it was in. When editing, it also makes sure that the
+// document can be written back out without changing any of its numbers.
+func (c *command) read(path string) (unstructured.Data, string, error) {
+	var raw []byte
+	var err error
+	if path == "-" {
+		raw, err = io.ReadAll(c.stdin)
+	} else {
+		raw, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return unstructured.Data{}, "", err
+	}
+
+	format := formatFor(path, raw)
+	var doc unstructured.Data
+	if format == formatJSON {
+		doc, err = unstructured.ParseJSON(string(raw))
+	} else {
+		doc, err = unstructured.ParseYAML(string(raw))
+	}
+	if err != nil {
+		return unstructured.Data{}, "", fmt.Errorf("couldn't parse %s as %s: %s", displayName(path), format, err)
+	}
+	// ParseYAML quietly ignores every document but the first, so an edit
+	// would throw the rest away.
+	if format == formatYAML {
+		if count := countYAMLDocuments(raw); count > 1 {
+			return unstructured.Data{}, "", fmt.Errorf("%s contains %d YAML documents, but only one is allowed", displayName(path), count)
+		}
+	}
+	if c.editing {
+		if err := checkNumbers(raw, format); err != nil {
+			return unstructured.Data{}, "", fmt.Errorf("can't edit %s: %s", displayName(path), err)
+		}
+	}
+	return doc, format, nil
+}
+
+func countYAMLDocuments(raw []byte) int {
+	decoder := yamlv3.NewDecoder(bytes.NewReader(raw))
+	count := 0
+	for {
+		var node yamlv3.Node
+		if err := decoder.Decode(&node); err != nil {
+			return count
+		}
+		count++
+	}
+}
+
+// checkNumbers returns an error if `raw` contains an integer which a float64
+// can't hold exactly, such as 2^53 + 1. Documents are held as float64s, so
+// writing such a number back out would quietly change it.
+func checkNumbers(raw []byte, format string) error {
+	var integers []string
+	if format == formatJSON {
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.UseNumber()
+		var val interface{}
+		if err := decoder.Decode(&val); err != nil {
+			return err
+		}
+		integers = jsonIntegers(val, integers)
+	} else {
+		var node yamlv3.Node
+		if err := yamlv3.Unmarshal(raw, &node); err != nil {
+			return err
+		}
+		integers = yamlIntegers(&node, integers)
+	}
+	for _, integer := range integers {
+		// Base 0 understands YAML's 0x and 0o prefixes and underscores, as
+		// well as plain decimal integers.
+		i, ok := new(big.Int).SetString(integer, 0)
+		if !ok {
+			continue
+		}
+		if _, accuracy := new(big.Float).SetInt(i).Float64(); accuracy != big.Exact {
+			return fmt.Errorf("the number %s is too big to be written back unchanged", integer)
+		}
+	}
+	return nil
+}
+
+func jsonIntegers(val interface{}, integers []string) []string {
+	switch v := val.(type) {
+	case json.Number:
+		if !strings.ContainsAny(string(v), ".eE") {
+			integers = append(integers, string(v))
+		}
+	case []interface{}:
+		for _, elem := range v {
+			integers = jsonIntegers(elem, integers)
+		}
+	case map[string]interface{}:
+		for _, elem := range v {
+			integers = jsonIntegers(elem, integers)
+		}
+	}
+	return integers
+}
+
+func yamlIntegers(node *yamlv3.Node, integers []string) []string {
+	if node.Kind == yamlv3.ScalarNode && node.ShortTag() == "!!int" {
+		integers = append(integers, node.Value)
+	}
+	for _, child := range node.Content {
+		integers = yamlIntegers(child, integers)
+	}
+	return integers
+}
+
+// formatFor decides whether a file is JSON or YAML, first by its extension,
+// and otherwise by whether its contents are valid JSON.
+func formatFor(path string, raw []byte) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return formatJSON
+	case ".yml", ".yaml":
+		return formatYAML
+	}
+	trimmed := bytes.TrimSpace(raw)
+	if (bytes.HasPrefix(trimmed, []byte("{")) || bytes.HasPrefix(trimmed, []byte("["))) && json.Valid(trimmed) {
+		return formatJSON
+	}
+	return formatYAML
+}
+
+// output writes an edited document to its file if `-i` was given, or to
+// standard output otherwise.
+func (c *command) output(path string, doc unstructured.Data, format string) error {
+	if !c.inPlace {
+		return c.write(c.stdout, doc, format)
+	}
+	if path == "-" {
+		return fmt.Errorf("can't edit standard input in place")
+	}
+	var buf bytes.Buffer
+	if err := c.write(&buf, doc, format); err != nil {
+		return err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), info.Mode().Perm())
+}
+
+func (c *command) write(w io.Writer, doc unstructured.Data, format string) error {
+	if c.outFormat != "" {
+		format = c.outFormat
+	}
+	raw, err := encodeJSON(doc.RawValue(), "  ")
+	if err != nil {
+		return err
+	}
+	if format == formatYAML {
+		raw, err = yaml.JSONToYAML(raw)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = w.Write(raw)
+	return err
+}
+
+// encodeJSON is like json.MarshalIndent, but leaves characters like `<` and
+// `&` alone rather than escaping them for HTML, and ends with a newline.
+func encodeJSON(val interface{}, indent string) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", indent)
+	if err := encoder.Encode(val); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func displayName(path string) string {
+	if path == "-" {
+		return "standard input"
+	}
+	return path
+}
@@ -0,0 +1,268 @@
+package main_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+)
+
+var _ = Describe("unstructured", func() {
+	var dir, manifest, jsonFile string
+
+	run := func(stdin string, args ...string) *gexec.Session {
+		cmd := exec.Command(binary, args...)
+		cmd.Stdin = strings.NewReader(stdin)
+		session, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(session).Should(gexec.Exit())
+		return session
+	}
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		manifest = filepath.Join(dir, "manifest.yml")
+		Expect(os.WriteFile(manifest, []byte(`name: my-deployment
+instance_groups:
+- name: web
+  instances: 2
+  jobs: [{name: nginx}, {name: debug}]
+`), 0644)).To(Succeed())
+		jsonFile = filepath.Join(dir, "data.json")
+		Expect(os.WriteFile(jsonFile, []byte(`{"employees": [{"name": "Alex"}], "count": 1}`), 0644)).To(Succeed())
+	})
+
+	Describe("get", func() {
+		It("prints the value at a pointer in the document's format", func() {
+			Expect(run("", "get", manifest, "/instance_groups/name=web/jobs/0").Out.Contents()).To(BeEquivalentTo("name: nginx\n"))
+			Expect(run("", "get", jsonFile, "/employees/0").Out.Contents()).To(BeEquivalentTo("{\n  \"name\": \"Alex\"\n}\n"))
+		})
+
+		It("can write a different format", func() {
+			Expect(run("", "get", "-o", "json", manifest, "/instance_groups/0/instances").Out.Contents()).To(BeEquivalentTo("2\n"))
+		})
+
+		It("reads standard input, detecting its format", func() {
+			Expect(run(`{"a": [1, 2]}`, "get", "-", "/a").Out.Contents()).To(BeEquivalentTo("[\n  1,\n  2\n]\n"))
+			Expect(run("a: [1, 2]", "get", "-", "/a").Out.Contents()).To(BeEquivalentTo("- 1\n- 2\n"))
+		})
+
+		It("fails when there's nothing at the pointer", func() {
+			session := run("", "get", manifest, "/missing")
+			Expect(session).To(gexec.Exit(1))
+			Expect(session.Err).To(gbytes.Say("unstructured: there's nothing at pointer '/missing'"))
+		})
+	})
+
+	Describe("set", func() {
+		It("writes the edited document to standard output", func() {
+			session := run("", "set", manifest, "/instance_groups/name=web/instances", "3")
+			Expect(session).To(gexec.Exit(0))
+			Expect(session.Out.Contents()).To(ContainSubstring("instances: 3"))
+			Expect(os.ReadFile(manifest)).To(ContainSubstring("instances: 2"))
+		})
+
+		It("edits the file in place with -i", func() {
+			Expect(run("", "set", "-i", manifest, "/stemcell?/os", "{name: jammy}")).To(gexec.Exit(0))
+			Expect(run("", "get", manifest, "/stemcell/os/name").Out.Contents()).To(BeEquivalentTo("jammy\n"))
+		})
+
+		It("doesn't escape HTML characters in JSON", func() {
+			Expect(run("", "set", "-i", "-s", jsonFile, "/query", "a<b && c>d")).To(gexec.Exit(0))
+			Expect(os.ReadFile(jsonFile)).To(ContainSubstring(`"query": "a<b && c>d"`))
+			Expect(run("", "diff", jsonFile, manifest).Out.Contents()).To(ContainSubstring(`"a<b && c>d"`))
+		})
+
+		It("can set values as strings", func() {
+			Expect(run("", "set", "-i", "-s", jsonFile, "/count", "2")).To(gexec.Exit(0))
+			Expect(run("", "type", jsonFile, "/count").Out.Contents()).To(BeEquivalentTo("string\n"))
+		})
+
+		It("can append to and replace the root of the document", func() {
+			Expect(run("[1, 2]", "set", "-o", "yaml", "-", "/-", "3").Out.Contents()).To(BeEquivalentTo("- 1\n- 2\n- 3\n"))
+			Expect(run("[1, 2]", "set", "-o", "yaml", "-", "", "{a: b}").Out.Contents()).To(BeEquivalentTo("a: b\n"))
+		})
+
+		It("reports errors with the user's pointer", func() {
+			session := run("", "set", manifest, "/instance_groups/name=db/instances", "1")
+			Expect(session).To(gexec.Exit(1))
+			Expect(session.Err).To(gbytes.Say("at pointer '/instance_groups/name=db'"))
+		})
+	})
+
+	Describe("delete", func() {
+		It("removes the value at the pointer", func() {
+			Expect(run("", "delete", "-i", manifest, "/instance_groups/name=web/jobs/name=debug")).To(gexec.Exit(0))
+			Expect(run("", "keys", manifest, "/instance_groups/0/jobs").Out.Contents()).To(BeEquivalentTo("0\n"))
+			Expect(run("", "delete", manifest, "/instance_groups/name=web/jobs/name=missing?")).To(gexec.Exit(0))
+		})
+
+		It("can't delete the whole document", func() {
+			session := run("", "delete", manifest, "")
+			Expect(session).To(gexec.Exit(1))
+			Expect(session.Err).To(gbytes.Say("can't delete the whole document"))
+		})
+	})
+
+	Describe("keys", func() {
+		It("lists the sorted keys of an object or the indexes of a list", func() {
+			Expect(run("", "keys", manifest).Out.Contents()).To(BeEquivalentTo("instance_groups\nname\n"))
+			Expect(run("", "keys", manifest, "/instance_groups/0/jobs").Out.Contents()).To(BeEquivalentTo("0\n1\n"))
+		})
+
+		It("fails for scalars", func() {
+			Expect(run("", "keys", manifest, "/name")).To(gexec.Exit(1))
+		})
+	})
+
+	Describe("type", func() {
+		It("prints the type of the value", func() {
+			Expect(run("", "type", manifest).Out.Contents()).To(BeEquivalentTo("object\n"))
+			Expect(run("", "type", jsonFile, "/employees").Out.Contents()).To(BeEquivalentTo("list\n"))
+		})
+	})
+
+	Describe("diff", func() {
+		var newManifest string
+
+		BeforeEach(func() {
+			newManifest = filepath.Join(dir, "new.yml")
+			Expect(os.WriteFile(newManifest, []byte(`name: my-deployment
+instance_groups:
+- name: web
+  instances: 3
+  jobs: [{name: nginx}]
+update: {canaries: 1}
+`), 0644)).To(Succeed())
+		})
+
+		It("lists the differences and exits with status 1", func() {
+			session := run("", "diff", manifest, newManifest)
+			Expect(session).To(gexec.Exit(1))
+			Expect(session.Out.Contents()).To(BeEquivalentTo(`~ /instance_groups/0/instances: 2 -> 3
+- /instance_groups/0/jobs/1: {"name":"debug"}
++ /update: {"canaries":1}
+`))
+		})
+
+		It("exits with status 0 when the documents are the same", func() {
+			session := run(`{"name": "my-deployment", "instance_groups": [{"name": "web", "instances": 2, "jobs": [{"name": "nginx"}, {"name": "debug"}]}]}`,
+				"diff", manifest, "-")
+			Expect(session).To(gexec.Exit(0))
+			Expect(session.Out.Contents()).To(BeEmpty())
+		})
+
+		It("accepts patterns to ignore and lists to compare in any order", func() {
+			Expect(run("", "diff", "-ignore", "/instance_groups/*/instances", "-ignore", "/update",
+				"-unordered", "/instance_groups/*/jobs", newManifest, manifest)).To(gexec.Exit(1))
+			Expect(run("", "diff", "-ignore", "/instance_groups/*/instances", "-ignore", "/update",
+				"-ignore", "/instance_groups/0/jobs/1", manifest, newManifest)).To(gexec.Exit(0))
+		})
+
+		It("can print a JSON patch, which patch can apply", func() {
+			session := run("", "diff", "-patch", manifest, newManifest)
+			Expect(session).To(gexec.Exit(1))
+			patchFile := filepath.Join(dir, "patch")
+			Expect(os.WriteFile(patchFile, session.Out.Contents(), 0644)).To(Succeed())
+			Expect(session.Out.Contents()).To(HavePrefix("[\n  {\n"))
+
+			Expect(run("", "patch", "-i", manifest, patchFile)).To(gexec.Exit(0))
+			Expect(run("", "diff", manifest, newManifest)).To(gexec.Exit(0))
+		})
+
+		It("exits with status 2 when something goes wrong", func() {
+			session := run("", "diff", manifest, filepath.Join(dir, "missing.yml"))
+			Expect(session).To(gexec.Exit(2))
+			Expect(session.Err).To(gbytes.Say("no such file or directory"))
+			Expect(run("", "diff", "-ignore", "name", manifest, newManifest)).To(gexec.Exit(2))
+			Expect(run("", "diff", "-", "-")).To(gexec.Exit(2))
+		})
+	})
+
+	Describe("patch", func() {
+		It("applies JSON patches", func() {
+			session := run(`[{"op": "add", "path": "/instance_groups/0/jobs/0", "value": {"name": "first"}}]`,
+				"patch", "-o", "json", manifest, "-")
+			Expect(session).To(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say(`"name": "first"`))
+		})
+
+		It("applies merge patches", func() {
+			session := run("{name: null, update: {canaries: 1}}", "patch", jsonFile, "-")
+			Expect(session).To(gexec.Exit(0))
+			Expect(session.Out.Contents()).To(MatchJSON(`{"employees": [{"name": "Alex"}], "count": 1, "update": {"canaries": 1}}`))
+		})
+
+		It("applies ops files", func() {
+			session := run(`
+- type: replace
+  path: /instance_groups/name=web/instances
+  value: 5
+- type: remove
+  path: /instance_groups/name=web/jobs/name=debug
+`, "patch", manifest, "-")
+			Expect(session).To(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say("instances: 5"))
+			Expect(session.Out.Contents()).NotTo(ContainSubstring("debug"))
+		})
+
+		It("can be told what kind of patch it has", func() {
+			session := run(`[1, 2]`, "patch", "-t", "merge", jsonFile, "-")
+			Expect(session).To(gexec.Exit(0))
+			Expect(session.Out.Contents()).To(MatchJSON(`[1, 2]`))
+
+			session = run(`[1, 2]`, "patch", jsonFile, "-")
+			Expect(session).To(gexec.Exit(1))
+			Expect(session.Err).To(gbytes.Say("use -t to say what kind of patch standard input is"))
+		})
+
+		It("reports operations which fail", func() {
+			session := run(`[{"op": "test", "path": "/count", "value": 2}]`, "patch", jsonFile, "-")
+			Expect(session).To(gexec.Exit(1))
+			Expect(session.Err).To(gbytes.Say(`Operation \[0\] \(test '/count'\) failed: Expected 2 at pointer '/count', but found 1`))
+		})
+	})
+
+	Describe("documents which can't be written back unchanged", func() {
+		It("refuses YAML with more than one document", func() {
+			Expect(os.WriteFile(manifest, []byte("a: 1\n---\nb: 2\n"), 0644)).To(Succeed())
+			session := run("", "set", "-i", manifest, "/a", "3")
+			Expect(session).To(gexec.Exit(1))
+			Expect(session.Err).To(gbytes.Say("manifest.yml contains 2 YAML documents, but only one is allowed"))
+			Expect(os.ReadFile(manifest)).To(BeEquivalentTo("a: 1\n---\nb: 2\n"))
+
+			Expect(run("a: 1\n---\nb: 2\n", "get", "-", "/a")).To(gexec.Exit(1))
+			Expect(run("---\na: 1\n", "get", "-", "/a").Out.Contents()).To(BeEquivalentTo("1\n"))
+		})
+
+		It("refuses to edit documents with integers which a float64 can't hold", func() {
+			Expect(os.WriteFile(jsonFile, []byte(`{"id": 9007199254740993, "count": 1}`), 0644)).To(Succeed())
+			session := run("", "set", "-i", jsonFile, "/count", "2")
+			Expect(session).To(gexec.Exit(1))
+			Expect(session.Err).To(gbytes.Say("can't edit .*data.json: the number 9007199254740993 is too big to be written back unchanged"))
+			Expect(os.ReadFile(jsonFile)).To(BeEquivalentTo(`{"id": 9007199254740993, "count": 1}`))
+
+			Expect(run("id: 0x20000000000001\n", "delete", "-", "/id")).To(gexec.Exit(1))
+			Expect(run("{id: 9007199254740993}", "patch", manifest, "-")).To(gexec.Exit(1))
+			Expect(run("", "set", manifest, "/id", "9007199254740993")).To(gexec.Exit(1))
+			Expect(run("", "get", jsonFile, "/count")).To(gexec.Exit(0))
+		})
+
+		It("edits documents with big integers which a float64 can hold", func() {
+			Expect(run(`{"id": 9007199254740992, "big": 1e300}`, "set", "-", "/count", "1").Out.Contents()).
+				To(MatchJSON(`{"id": 9007199254740992, "big": 1e300, "count": 1}`))
+		})
+	})
+
+	It("shows the usage when the command line is wrong", func() {
+		session := run("", "get", manifest)
+		Expect(session).To(gexec.Exit(2))
+		Expect(session.Err).To(gbytes.Say("Usage:"))
+		Expect(run("", "frobnicate")).To(gexec.Exit(2))
+	})
+})
@@ -0,0 +1,165 @@
+package unstructured
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The As... methods are lenient versions of StringValue, NumValue and
+// BoolValue. Hand-written YAML often quotes values which are really numbers or
+// bools, such as `port: "8080"` or `enabled: "yes"`, and these methods convert
+// such values rather than rejecting them. Each method documents exactly which
+// conversions it performs. When a value can't be converted, the error names
+// the type of the original value.
+
+// AsString returns the data as a string. Strings are returned unchanged,
+// numbers are formatted in the shortest form which parses back to the same
+// number (so `8080` becomes "8080" and `1.5` becomes "1.5"), and bools become
+// "true" or "false". Objects, lists and null can't be converted.
+func (j Data) AsString() (string, error) {
+	switch v := j.data.(type) {
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	}
+	return "", j.conversionError("a string")
+}
+
+// AsNum returns the data as a float64. Numbers are returned unchanged, and
+// strings are parsed as decimal numbers, ignoring surrounding whitespace. Bools,
+// objects, lists and null can't be converted.
+func (j Data) AsNum() (float64, error) {
+	switch v := j.data.(type) {
+	case float64:
+		return v, nil
+	case string:
+		num, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil || math.IsNaN(num) || math.IsInf(num, 0) {
+			return 0, fmt.Errorf("This is the string %q, which we can't convert to a number", v)
+		}
+		return num, nil
+	}
+	return 0, j.conversionError("a number")
+}
+
+// AsInt returns the data as an int. It converts the data as AsNum does, and
+// then requires the number to be whole, and small enough to fit in an int.
+func (j Data) AsInt() (int, error) {
+	num, err := j.AsNum()
+	if err != nil {
+		return 0, err
+	}
+	// MaxInt isn't exactly representable as a float64, and rounds up to
+	// -MinInt, which is too big, so compare against that instead.
+	if num != math.Trunc(num) || num < math.MinInt || num >= -math.MinInt {
+		return 0, fmt.Errorf("This is the number %s, which we can't convert to an int",
+			strconv.FormatFloat(num, 'f', -1, 64))
+	}
+	return int(num), nil
+}
+
+// AsBool returns the data as a bool. Bools are returned unchanged. Strings are
+// converted following YAML 1.1, ignoring case and surrounding whitespace:
+// "true", "yes", "y" and "on" become true, while "false", "no", "n" and "off"
+// become false. The numbers 1 and 0, and the strings "1" and "0", become true
+// and false respectively. Nothing else can be converted.
+func (j Data) AsBool() (bool, error) {
+	switch v := j.data.(type) {
+	case bool:
+		return v, nil
+	case float64:
+		switch v {
+		case 1:
+			return true, nil
+		case 0:
+			return false, nil
+		}
+		return false, fmt.Errorf("This is the number %s, which we can't convert to a bool",
+			strconv.FormatFloat(v, 'f', -1, 64))
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "true", "yes", "y", "on", "1":
+			return true, nil
+		case "false", "no", "n", "off", "0":
+			return false, nil
+		}
+		return false, fmt.Errorf("This is the string %q, which we can't convert to a bool", v)
+	}
+	return false, j.conversionError("a bool")
+}
+
+// AsDuration returns the data as a time.Duration. Strings are parsed by
+// time.ParseDuration, so "90s" and "1h30m" are both valid. Numbers are taken to
+// be a number of seconds, and must be small enough for a time.Duration, which
+// is at most about 292 years. Nothing else can be converted.
+func (j Data) AsDuration() (time.Duration, error) {
+	switch v := j.data.(type) {
+	case float64:
+		nanos := v * float64(time.Second)
+		if nanos < math.MinInt64 || nanos >= -math.MinInt64 {
+			return 0, fmt.Errorf("This is the number %s, which we can't convert to a duration",
+				strconv.FormatFloat(v, 'f', -1, 64))
+		}
+		return time.Duration(nanos), nil
+	case string:
+		duration, err := time.ParseDuration(strings.TrimSpace(v))
+		if err != nil {
+			return 0, fmt.Errorf("This is the string %q, which we can't convert to a duration", v)
+		}
+		return duration, nil
+	}
+	return 0, j.conversionError("a duration")
+}
+
+// AsTime returns the data as a time.Time. Strings are parsed as RFC 3339
+// timestamps such as "2017-06-01T12:00:00Z", or as dates such as "2017-06-01",
+// which are taken to be midnight UTC. Numbers are taken to be a number of
+// seconds since the Unix epoch, which must be within the range of a
+// time.Time, and are returned in UTC. Nothing else can be converted.
+func (j Data) AsTime() (time.Time, error) {
+	switch v := j.data.(type) {
+	case float64:
+		secs, frac := math.Modf(v)
+		if secs < math.MinInt64 || secs >= maxUnixSeconds {
+			return time.Time{}, fmt.Errorf("This is the number %s, which we can't convert to a time",
+				strconv.FormatFloat(v, 'f', -1, 64))
+		}
+		return time.Unix(int64(secs), int64(frac*float64(time.Second))).UTC(), nil
+	case string:
+		trimmed := strings.TrimSpace(v)
+		if t, err := time.Parse(time.RFC3339Nano, trimmed); err == nil {
+			return t, nil
+		}
+		if t, err := time.Parse(time.DateOnly, trimmed); err == nil {
+			return t, nil
+		}
+		return time.Time{}, fmt.Errorf("This is the string %q, which we can't convert to a time", v)
+	}
+	return time.Time{}, j.conversionError("a time")
+}
+
+// maxUnixSeconds is the number of seconds after the Unix epoch beyond which
+// time.Unix overflows, since a time.Time counts seconds from the year 1 in an
+// int64.
+const maxUnixSeconds = math.MaxInt64 - 62135596800
+
+func (j Data) conversionError(target string) error {
+	switch {
+	case j.IsOb():
+		return fmt.Errorf("This is an object, so we can't convert it to %s", target)
+	case j.IsList():
+		return fmt.Errorf("This is a list, so we can't convert it to %s", target)
+	case j.IsNull():
+		return fmt.Errorf("This is null, so we can't convert it to %s", target)
+	case j.IsBool():
+		return fmt.Errorf("This is a bool, so we can't convert it to %s", target)
+	default:
+		return fmt.Errorf("This is a %T, so we can't convert it to %s", j.data, target)
+	}
+}
@@ -0,0 +1,132 @@
+package unstructured_test
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Coercing accessors", func() {
+	Describe("AsString", func() {
+		It("converts strings, numbers and bools", func() {
+			Expect(mustParseYAML(`hello`).AsString()).To(Equal("hello"))
+			Expect(mustParseYAML(`8080`).AsString()).To(Equal("8080"))
+			Expect(mustParseYAML(`1.5`).AsString()).To(Equal("1.5"))
+			Expect(mustParseYAML(`true`).AsString()).To(Equal("true"))
+		})
+
+		It("rejects objects, lists and null", func() {
+			_, err := mustParseYAML(`{a: b}`).AsString()
+			Expect(err).To(MatchError("This is an object, so we can't convert it to a string"))
+			_, err = mustParseYAML(`[a]`).AsString()
+			Expect(err).To(MatchError("This is a list, so we can't convert it to a string"))
+			_, err = mustParseYAML(`null`).AsString()
+			Expect(err).To(MatchError("This is null, so we can't convert it to a string"))
+		})
+	})
+
+	Describe("AsNum", func() {
+		It("converts numbers and numeric strings", func() {
+			Expect(mustParseYAML(`8080`).AsNum()).To(Equal(8080.0))
+			Expect(mustParseYAML(`"8080"`).AsNum()).To(Equal(8080.0))
+			Expect(mustParseYAML(`" -1.5e3 "`).AsNum()).To(Equal(-1500.0))
+		})
+
+		It("rejects everything else", func() {
+			_, err := mustParseYAML(`eighty`).AsNum()
+			Expect(err).To(MatchError(`This is the string "eighty", which we can't convert to a number`))
+			_, err = mustParseYAML(`"NaN"`).AsNum()
+			Expect(err).To(HaveOccurred())
+			_, err = mustParseYAML(`true`).AsNum()
+			Expect(err).To(MatchError("This is a bool, so we can't convert it to a number"))
+		})
+	})
+
+	Describe("AsInt", func() {
+		It("converts whole numbers", func() {
+			Expect(mustParseYAML(`"8080"`).AsInt()).To(Equal(8080))
+			Expect(mustParseYAML(`-3`).AsInt()).To(Equal(-3))
+		})
+
+		It("rejects fractions", func() {
+			_, err := mustParseYAML(`"1.5"`).AsInt()
+			Expect(err).To(MatchError("This is the number 1.5, which we can't convert to an int"))
+		})
+
+		It("rejects numbers too big or small for an int", func() {
+			Expect(mustParseYAML(strconv.Itoa(math.MinInt)).AsInt()).To(Equal(math.MinInt))
+			_, err := mustParseYAML(strconv.FormatFloat(-float64(math.MinInt), 'f', -1, 64)).AsInt()
+			Expect(err).To(MatchError(ContainSubstring("which we can't convert to an int")))
+			_, err = mustParseYAML(strconv.FormatFloat(2*float64(math.MinInt), 'f', -1, 64)).AsInt()
+			Expect(err).To(MatchError(ContainSubstring("which we can't convert to an int")))
+		})
+	})
+
+	Describe("AsBool", func() {
+		It("converts YAML 1.1 bool strings", func() {
+			for _, truthy := range []string{`true`, `"yes"`, `"Y"`, `"on"`, `"TRUE"`, `"1"`, `1`} {
+				Expect(mustParseYAML(truthy).AsBool()).To(BeTrue(), truthy)
+			}
+			for _, falsy := range []string{`false`, `"no"`, `"n"`, `"Off"`, `"false"`, `"0"`, `0`} {
+				Expect(mustParseYAML(falsy).AsBool()).To(BeFalse(), falsy)
+			}
+		})
+
+		It("rejects everything else", func() {
+			_, err := mustParseYAML(`maybe`).AsBool()
+			Expect(err).To(MatchError(`This is the string "maybe", which we can't convert to a bool`))
+			_, err = mustParseYAML(`2`).AsBool()
+			Expect(err).To(MatchError("This is the number 2, which we can't convert to a bool"))
+			_, err = mustParseYAML(`[]`).AsBool()
+			Expect(err).To(MatchError("This is a list, so we can't convert it to a bool"))
+		})
+	})
+
+	Describe("AsDuration", func() {
+		It("parses strings and treats numbers as seconds", func() {
+			Expect(mustParseYAML(`1h30m`).AsDuration()).To(Equal(90 * time.Minute))
+			Expect(mustParseYAML(`1.5`).AsDuration()).To(Equal(1500 * time.Millisecond))
+		})
+
+		It("rejects invalid durations", func() {
+			_, err := mustParseYAML(`soon`).AsDuration()
+			Expect(err).To(MatchError(`This is the string "soon", which we can't convert to a duration`))
+		})
+
+		It("rejects numbers of seconds too big for a duration", func() {
+			Expect(mustParseYAML(`9e9`).AsDuration()).To(Equal(9e9 * time.Second))
+			_, err := mustParseYAML(`1e10`).AsDuration()
+			Expect(err).To(MatchError(`This is the number 10000000000, which we can't convert to a duration`))
+			_, err = mustParseYAML(`-1e10`).AsDuration()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("AsTime", func() {
+		It("parses timestamps and dates", func() {
+			Expect(mustParseYAML(`"2017-06-01T12:30:00Z"`).AsTime()).To(Equal(time.Date(2017, 6, 1, 12, 30, 0, 0, time.UTC)))
+			Expect(mustParseYAML(`"2017-06-01"`).AsTime()).To(Equal(time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)))
+		})
+
+		It("treats numbers as unix seconds", func() {
+			Expect(mustParseYAML(`1496320200`).AsTime()).To(Equal(time.Date(2017, 6, 1, 12, 30, 0, 0, time.UTC)))
+		})
+
+		It("rejects invalid times", func() {
+			_, err := mustParseYAML(`yesterday`).AsTime()
+			Expect(err).To(MatchError(`This is the string "yesterday", which we can't convert to a time`))
+		})
+
+		It("rejects numbers of seconds too big for a time", func() {
+			_, err := mustParseYAML(`1e19`).AsTime()
+			Expect(err).To(MatchError(`This is the number 10000000000000000000, which we can't convert to a time`))
+			_, err = mustParseYAML(`9.223372e18`).AsTime()
+			Expect(err).To(HaveOccurred())
+			_, err = mustParseYAML(`-1e19`).AsTime()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
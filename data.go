@@ -7,16 +7,29 @@ import (
 	"reflect"
 
 	"github.com/ghodss/yaml"
-	"github.com/xeipuuv/gojsonpointer"
 )
 
+// Kind is the type of some unstructured data, as returned by Data.Type.
+type Kind string
+
+const (
+	KindString Kind = "string"
+	KindNum    Kind = "number"
+	KindOb     Kind = "object"
+	KindList   Kind = "list"
+	KindNull   Kind = "null"
+	KindBool   Kind = "bool"
+)
+
+// The Data... constants are the original names of the Kinds, and are kept for
+// compatibility.
 const (
-	DataString = "string"
-	DataNum    = "number"
-	DataOb     = "object"
-	DataList   = "list"
-	DataNull   = "null"
-	DataBool   = "bool"
+	DataString = KindString
+	DataNum    = KindNum
+	DataOb     = KindOb
+	DataList   = KindList
+	DataNull   = KindNull
+	DataBool   = KindBool
 )
 
 // Data represents some unstructured data
@@ -81,30 +94,142 @@ func (j Data) HasKey(key string) bool {
 // HasPointer returns true iff the object represented by this Data struct
 // contains the pointer `p`
 //
+// As well as plain json pointers, `p` may select list elements by the value
+// of one of their fields, and mark segments as optional. See GetByPointer for
+// details.
+//
 // For more information on json pointers, see https://tools.ietf.org/html/rfc6901
 func (j Data) HasPointer(p string) (bool, error) {
-	pointer, err := gojsonpointer.NewJsonPointer(p)
+	tokens, err := SplitPointer(p)
 	if err != nil {
 		return false, err
 	}
-	_, _, err = pointer.Get(j.data)
-	return err == nil, nil
+	_, found, err := getByTokens(j.data, tokens)
+	return found && err == nil, nil
 }
 
 // GetByPointer returns a Data struct containing the contents of the original
 // data at the given pointer address `p`.
 // For more information on json pointers, see https://tools.ietf.org/html/rfc6901
+//
+// As well as plain json pointers, GetByPointer understands the extensions
+// used by BOSH ops files. A segment like `name=web` selects the single
+// element of a list whose `name` field is `web`, so
+// `/instance_groups/name=web/jobs/name=nginx` keeps working when the order of
+// the instance groups changes. A segment ending in `?` is optional, and so
+// are all the segments after it: if one doesn't match anything, GetByPointer
+// returns a null Data struct rather than an error.
 func (j Data) GetByPointer(p string) (data Data, err error) {
-
-	pointer, err := gojsonpointer.NewJsonPointer(p)
+	tokens, err := SplitPointer(p)
 	if err != nil {
 		return
 	}
-	json, _, err := pointer.Get(j.data)
-	data = Data{data: json}
+	val, _, err := getByTokens(j.data, tokens)
+	data = Data{data: val}
 	return
 }
 
+// SetByPointer sets the value at the pointer address `p` to `val`. The parent
+// of the value must already exist, unless the missing segments are marked as
+// optional with a trailing `?`, in which case SetByPointer creates them.
+//
+// `p` may use the same extensions as GetByPointer. In addition, the last
+// segment may be `-` to append `val` to a list, and an optional selector like
+// `name=web?` which matches nothing appends a new object `{"name": "web"}`
+// to the list.
+//
+// Since this Data struct can't be replaced by its own methods, it's an error
+// to set the empty pointer, or to append to a list at the root of the Data.
+// WithPointerSet can do both.
+func (j Data) SetByPointer(p string, val interface{}) error {
+	tokens, err := SplitPointer(p)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		return fmt.Errorf("You can't set the root of a Data struct -- try making a new one instead")
+	}
+	if list, ok := j.data.([]interface{}); ok {
+		index, err := listIndex(list, tokens[:1])
+		if err != nil {
+			return err
+		}
+		if index < 0 || index == len(list) {
+			return fmt.Errorf("You can't add elements to a list at the root of a Data struct")
+		}
+	}
+	_, err = setByTokens(j.data, tokens, val)
+	return err
+}
+
+// DeleteByPointer removes the value at the pointer address `p`. If the last
+// segment of `p` refers to a list element, the rest of the list closes up
+// behind it.
+//
+// `p` may use the same extensions as GetByPointer. If an optional segment
+// doesn't match anything, there's nothing to delete, and DeleteByPointer
+// succeeds without changing anything.
+//
+// Since this Data struct can't be replaced by its own methods, it's an error
+// to delete the empty pointer, or to delete an element of a list at the root
+// of the Data. WithPointerDeleted can do the latter.
+func (j Data) DeleteByPointer(p string) error {
+	tokens, err := SplitPointer(p)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		return fmt.Errorf("You can't delete the root of a Data struct")
+	}
+	if list, ok := j.data.([]interface{}); ok && len(tokens) == 1 {
+		index, err := listIndex(list, tokens)
+		if err != nil {
+			return err
+		}
+		if index >= 0 {
+			return fmt.Errorf("You can't remove elements from a list at the root of a Data struct")
+		}
+	}
+	_, err = deleteByTokens(j.data, tokens)
+	return err
+}
+
+// WithPointerSet is like SetByPointer, but returns the resulting Data, so
+// that it can also replace the whole document when `p` is empty, and add
+// elements to a list at the root. Objects and lists are still changed in
+// place where possible, so use the returned Data rather than this one
+// afterwards.
+func (j Data) WithPointerSet(p string, val interface{}) (Data, error) {
+	tokens, err := SplitPointer(p)
+	if err != nil {
+		return Data{}, err
+	}
+	newData, err := setByTokens(j.data, tokens, val)
+	if err != nil {
+		return Data{}, err
+	}
+	return Data{data: newData}, nil
+}
+
+// WithPointerDeleted is like DeleteByPointer, but returns the resulting Data,
+// so that it can also remove elements from a list at the root. As with
+// WithPointerSet, use the returned Data rather than this one afterwards. It's
+// still an error to delete the empty pointer.
+func (j Data) WithPointerDeleted(p string) (Data, error) {
+	tokens, err := SplitPointer(p)
+	if err != nil {
+		return Data{}, err
+	}
+	if len(tokens) == 0 {
+		return Data{}, fmt.Errorf("You can't delete the root of a Data struct")
+	}
+	newData, err := deleteByTokens(j.data, tokens)
+	if err != nil {
+		return Data{}, err
+	}
+	return Data{data: newData}, nil
+}
+
 // UnsafeGetField returns a Data struct containing the contents of the original data
 // at the given `key`. If this method name feels too long, use `F(key)`.
 //
@@ -153,6 +278,32 @@ func (j Data) SetField(fieldName string, val interface{}) error {
 	return nil
 }
 
+// DeepCopy returns a Data struct representing the same data as this one, but
+// sharing none of its objects or lists, so that changes to one don't show up
+// in the other.
+func (j Data) DeepCopy() Data {
+	return Data{data: deepCopy(j.data)}
+}
+
+func deepCopy(val interface{}) interface{} {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		ob := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			ob[key] = deepCopy(child)
+		}
+		return ob
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, child := range v {
+			list[i] = deepCopy(child)
+		}
+		return list
+	default:
+		return v
+	}
+}
+
 // RawValue returns the raw go value of the parsed data, without any type
 // checking
 func (j Data) RawValue() interface{} {
@@ -256,7 +407,8 @@ func (j Data) ListValue() ([]Data, error) {
 }
 
 // An ElementMatcher can be used with FindElem to find an element in an
-// unstructured list.
+// unstructured list. The Match functions build common ElementMatchers, and
+// And, Or and Not combine them.
 type ElementMatcher func(Data) bool
 
 // FindElem finds an element in a list, using a provided matcher
@@ -287,23 +439,36 @@ func (j Data) IsNull() bool {
 	return j.data == nil
 }
 
+// Type returns the Kind of data represented by this Data struct. Data which
+// couldn't have come from parsing json, such as an int set with SetField, has
+// the empty Kind.
+func (j Data) Type() Kind {
+	switch {
+	case j.IsOb():
+		return KindOb
+	case j.IsString():
+		return KindString
+	case j.IsList():
+		return KindList
+	case j.IsNum():
+		return KindNum
+	case j.IsBool():
+		return KindBool
+	case j.IsNull():
+		return KindNull
+	default:
+		return ""
+	}
+}
+
 // IsOfType returns true iff the Data struct represents data of type `typ`.
-// Valid values of `typ` are listed as constants above.
-func (j Data) IsOfType(typ string) bool {
+// Valid values of `typ` are listed as constants above. If `typ` isn't one of
+// them, IsOfType returns an error.
+func (j Data) IsOfType(typ Kind) (bool, error) {
 	switch typ {
-	case DataOb:
-		return j.IsOb()
-	case DataString:
-		return j.IsString()
-	case DataList:
-		return j.IsList()
-	case DataNum:
-		return j.IsNum()
-	case DataBool:
-		return j.IsBool()
-	case DataNull:
-		return j.IsNull()
+	case KindOb, KindString, KindList, KindNum, KindBool, KindNull:
+		return j.Type() == typ, nil
 	default:
-		panic("that's not a Data type I recognise!")
+		return false, fmt.Errorf("'%s' is not a Data type I recognise", typ)
 	}
 }
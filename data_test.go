@@ -206,6 +206,15 @@ not: null
 			})
 		})
 
+		It("can make a deep copy which shares nothing with the original", func() {
+			copied := json.DeepCopy()
+			Expect(copied).To(Equal(json))
+			Expect(copied.F("things").SetField("more", "stuff")).To(Succeed())
+			Expect(copied.F("othernames").SetElem(0, "alex")).To(Succeed())
+			Expect(json.F("things").F("more").UnsafeStringValue()).To(Equal("things"))
+			Expect(json.F("othernames").UnsafeListValue()[0].UnsafeStringValue()).To(Equal("alice"))
+		})
+
 		It("has a raw value equal to the parsed JSON", func() {
 			Expect(json.RawValue()).To(HaveLen(6))
 			Expect(json.RawValue()).To(HaveKey("name"))
@@ -512,9 +521,32 @@ not: null
 		)
 
 		Context("when we give a string that isn't a Data type", func() {
-			It("panics", func() {
-				Expect(func() { json.IsOfType("badgers") }).To(Panic())
+			It("returns an error", func() {
+				_, err := json.IsOfType("badgers")
+				Expect(err).To(MatchError("'badgers' is not a Data type I recognise"))
 			})
 		})
 	})
+
+	Describe("Type", func() {
+		DescribeTable("returns the kind of data", func(key string, kind unstructured.Kind) {
+			json, err := unstructured.ParseJSON(rawjson)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(json.F(key).Type()).To(Equal(kind))
+		},
+			Entry("an object key", "things", unstructured.KindOb),
+			Entry("a string key", "name", unstructured.KindString),
+			Entry("a list key", "othernames", unstructured.KindList),
+			Entry("a number key", "life", unstructured.KindNum),
+			Entry("a boolean key", "beauty", unstructured.KindBool),
+			Entry("a null key", "not", unstructured.KindNull),
+		)
+
+		It("returns the empty Kind for data which didn't come from json", func() {
+			json, err := unstructured.ParseJSON(`{}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(json.SetField("count", 3)).To(Succeed())
+			Expect(json.F("count").Type()).To(Equal(unstructured.Kind("")))
+		})
+	})
 })
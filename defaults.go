@@ -0,0 +1,95 @@
+package unstructured
+
+import "fmt"
+
+// StringValueOr returns the string represented by this Data struct, or `def`
+// if it doesn't represent a string.
+func (j Data) StringValueOr(def string) string {
+	if !j.IsString() {
+		return def
+	}
+	return j.UnsafeStringValue()
+}
+
+// NumValueOr returns the number represented by this Data struct, or `def` if
+// it doesn't represent a number.
+func (j Data) NumValueOr(def float64) float64 {
+	if !j.IsNum() {
+		return def
+	}
+	return j.UnsafeNumValue()
+}
+
+// BoolValueOr returns the bool represented by this Data struct, or `def` if it
+// doesn't represent a bool.
+func (j Data) BoolValueOr(def bool) bool {
+	if !j.IsBool() {
+		return def
+	}
+	return j.UnsafeBoolValue()
+}
+
+// StringByPointerOr returns the string at the json pointer `p`, or `def` if
+// there's no string there -- either because nothing is at `p`, or because `p`
+// is invalid, or because the value at `p` isn't a string.
+func (j Data) StringByPointerOr(p string, def string) string {
+	val, err := j.GetByPointer(p)
+	if err != nil {
+		return def
+	}
+	return val.StringValueOr(def)
+}
+
+// NumByPointerOr returns the number at the json pointer `p`, or `def` if
+// there's no number there.
+func (j Data) NumByPointerOr(p string, def float64) float64 {
+	val, err := j.GetByPointer(p)
+	if err != nil {
+		return def
+	}
+	return val.NumValueOr(def)
+}
+
+// BoolByPointerOr returns the bool at the json pointer `p`, or `def` if there's
+// no bool there.
+func (j Data) BoolByPointerOr(p string, def bool) bool {
+	val, err := j.GetByPointer(p)
+	if err != nil {
+		return def
+	}
+	return val.BoolValueOr(def)
+}
+
+// ApplyDefaults fills in every key which is in `defaults` but missing from
+// `doc`, recursing into objects which both of them have. Values which are
+// already in `doc` are never overwritten, even if they are null or of a
+// different type to the default, and lists are left as they are.
+//
+// `doc` is modified in place, and copies of the default values are used, so
+// later changes to `doc` won't affect `defaults`. If `doc` isn't an object,
+// ApplyDefaults returns an error.
+func ApplyDefaults(doc, defaults Data) error {
+	if !doc.IsOb() {
+		return fmt.Errorf("This is not an object, so we can't apply defaults to it")
+	}
+	if !defaults.IsOb() {
+		return fmt.Errorf("The defaults are not an object, so we can't apply them")
+	}
+	applyDefaults(doc.UnsafeObValue(), defaults.UnsafeObValue())
+	return nil
+}
+
+func applyDefaults(doc, defaults map[string]interface{}) {
+	for key, def := range defaults {
+		existing, ok := doc[key]
+		if !ok {
+			doc[key] = deepCopy(def)
+			continue
+		}
+		existingOb, existingIsOb := existing.(map[string]interface{})
+		defOb, defIsOb := def.(map[string]interface{})
+		if existingIsOb && defIsOb {
+			applyDefaults(existingOb, defOb)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package unstructured_test
+
+import (
+	"github.com/totherme/unstructured"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Defaults", func() {
+	var doc unstructured.Data
+
+	BeforeEach(func() {
+		doc = mustParseYAML(`
+name: web
+port: "8080"
+enabled: true
+update: {canaries: 1, serial: null}
+instance_groups:
+- name: web
+  instances: 2
+`)
+	})
+
+	Describe("the Or accessors", func() {
+		It("return the value when it has the right type", func() {
+			Expect(doc.F("name").StringValueOr("default")).To(Equal("web"))
+			Expect(doc.F("update").F("canaries").NumValueOr(7)).To(Equal(1.0))
+			Expect(doc.F("enabled").BoolValueOr(false)).To(BeTrue())
+		})
+
+		It("return the default otherwise", func() {
+			Expect(doc.F("port").NumValueOr(80)).To(Equal(80.0))
+			Expect(doc.F("update").StringValueOr("default")).To(Equal("default"))
+			Expect(doc.F("update").F("serial").BoolValueOr(true)).To(BeTrue())
+		})
+
+		Describe("by pointer", func() {
+			It("return the value at the pointer", func() {
+				Expect(doc.StringByPointerOr("/instance_groups/name=web/name", "default")).To(Equal("web"))
+				Expect(doc.NumByPointerOr("/instance_groups/0/instances", 1)).To(Equal(2.0))
+				Expect(doc.BoolByPointerOr("/enabled", false)).To(BeTrue())
+			})
+
+			It("return the default when the pointer is missing, invalid or of the wrong type", func() {
+				Expect(doc.StringByPointerOr("/missing", "default")).To(Equal("default"))
+				Expect(doc.NumByPointerOr("no-slash", 3)).To(Equal(3.0))
+				Expect(doc.BoolByPointerOr("/name", true)).To(BeTrue())
+			})
+		})
+	})
+
+	Describe("ApplyDefaults", func() {
+		It("fills in missing keys recursively", func() {
+			Expect(unstructured.ApplyDefaults(doc, mustParseYAML(`
+name: default-name
+stemcell: default
+update: {canaries: 5, serial: true, max_in_flight: 1}
+enabled: {nested: true}
+instance_groups: [{name: default}]
+`))).To(Succeed())
+			Expect(doc).To(Equal(mustParseYAML(`
+name: web
+port: "8080"
+enabled: true
+stemcell: default
+update: {canaries: 1, serial: null, max_in_flight: 1}
+instance_groups:
+- name: web
+  instances: 2
+`)))
+		})
+
+		It("copies the default values", func() {
+			defaults := mustParseYAML(`{properties: {a: 1}}`)
+			Expect(unstructured.ApplyDefaults(doc, defaults)).To(Succeed())
+			Expect(doc.F("properties").SetField("a", 2)).To(Succeed())
+			Expect(defaults).To(Equal(mustParseYAML(`{properties: {a: 1}}`)))
+		})
+
+		It("returns an error when either side isn't an object", func() {
+			Expect(unstructured.ApplyDefaults(mustParseYAML(`[]`), doc)).To(MatchError("This is not an object, so we can't apply defaults to it"))
+			Expect(unstructured.ApplyDefaults(doc, mustParseYAML(`7`))).To(MatchError("The defaults are not an object, so we can't apply them"))
+		})
+	})
+})
@@ -0,0 +1,363 @@
+package unstructured
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// A ChangeType says how a value differs between two documents.
+type ChangeType int
+
+const (
+	// Changed means that both documents have a value at the pointer, but the
+	// values differ.
+	Changed ChangeType = iota
+	// Added means that only the second document has a value at the pointer.
+	Added
+	// Removed means that only the first document has a value at the pointer.
+	Removed
+)
+
+// A Difference describes one place where two documents differ.
+type Difference struct {
+	// Pointer is the json pointer of the differing value. For added values
+	// it points into the second document, and otherwise it points into the
+	// first.
+	Pointer string
+	// Type says whether the value was changed, added or removed.
+	Type ChangeType
+	// From is the value in the first document. It is null if the value was
+	// added.
+	From Data
+	// To is the value in the second document. It is null if the value was
+	// removed.
+	To Data
+}
+
+// String describes the Difference as a test failure would, treating the first
+// document as the expected one and the second as the actual one. Values are
+// shown as compact json.
+func (d Difference) String() string {
+	switch d.Type {
+	case Added:
+		return fmt.Sprintf("'%s': unexpected %s", d.Pointer, compactJSON(d.To.data))
+	case Removed:
+		return fmt.Sprintf("'%s': expected %s, but it was missing", d.Pointer, compactJSON(d.From.data))
+	default:
+		return fmt.Sprintf("'%s': expected %s, got %s", d.Pointer, compactJSON(d.From.data), compactJSON(d.To.data))
+	}
+}
+
+func compactJSON(val interface{}) string {
+	encoded, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Sprintf("%+v", val)
+	}
+	return string(encoded)
+}
+
+// A DiffOption changes the behaviour of Diff. Like MergeOptions, each takes a
+// pointer pattern, which may use the wildcards described in GlobPointer.
+type DiffOption func(*diffConfig)
+
+type diffConfig struct {
+	ignored    [][]string
+	unordered  [][]string
+	patternErr error
+}
+
+// IgnoreAt makes Diff ignore any differences at pointers matching `pattern`,
+// or inside the values there.
+func IgnoreAt(pattern string) DiffOption {
+	return func(c *diffConfig) {
+		c.ignored = append(c.ignored, c.parsePattern(pattern))
+	}
+}
+
+// UnorderedListsAt makes Diff compare lists at pointers matching `pattern` as
+// multisets, so that the same elements in a different order don't count as a
+// difference. Elements of the first list with no equal element in the second
+// are reported as removed, and elements of the second list with no equal
+// element in the first are reported as added.
+func UnorderedListsAt(pattern string) DiffOption {
+	return func(c *diffConfig) {
+		c.unordered = append(c.unordered, c.parsePattern(pattern))
+	}
+}
+
+func (c *diffConfig) parsePattern(pattern string) []string {
+	tokens, err := SplitPointer(pattern)
+	if err != nil && c.patternErr == nil {
+		c.patternErr = fmt.Errorf("Invalid pattern '%s': %s", pattern, err)
+	}
+	return tokens
+}
+
+func (c *diffConfig) matchesAny(patterns [][]string, path []string) bool {
+	for _, pattern := range patterns {
+		if matchTokens(pattern, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// Diff compares two documents, and returns every difference between them in
+// document order. Objects are compared key by key, and lists element by
+// element, so that each Difference is as deep in the documents as possible.
+// Neither document is changed.
+func Diff(from, to Data, opts ...DiffOption) ([]Difference, error) {
+	config := &diffConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+	if config.patternErr != nil {
+		return nil, config.patternErr
+	}
+	return config.diff(from.data, to.data, nil), nil
+}
+
+func (c *diffConfig) diff(from, to interface{}, path []string) []Difference {
+	if c.matchesAny(c.ignored, path) {
+		return nil
+	}
+	switch t := to.(type) {
+	case map[string]interface{}:
+		if f, ok := from.(map[string]interface{}); ok {
+			return c.diffObjects(f, t, path)
+		}
+	case []interface{}:
+		if f, ok := from.([]interface{}); ok {
+			if c.matchesAny(c.unordered, path) {
+				return c.diffUnorderedLists(f, t, path)
+			}
+			return c.diffLists(f, t, path)
+		}
+	}
+	if reflect.DeepEqual(from, to) {
+		return nil
+	}
+	return c.difference(Changed, path, from, to)
+}
+
+func (c *diffConfig) diffObjects(from, to map[string]interface{}, path []string) []Difference {
+	keys := sortedKeys(from)
+	for key := range to {
+		if _, ok := from[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var diffs []Difference
+	for _, key := range keys {
+		keyPath := appendToken(path, key)
+		fromVal, inFrom := from[key]
+		toVal, inTo := to[key]
+		switch {
+		case !inFrom:
+			diffs = append(diffs, c.difference(Added, keyPath, nil, toVal)...)
+		case !inTo:
+			diffs = append(diffs, c.difference(Removed, keyPath, fromVal, nil)...)
+		default:
+			diffs = append(diffs, c.diff(fromVal, toVal, keyPath)...)
+		}
+	}
+	return diffs
+}
+
+func (c *diffConfig) diffLists(from, to []interface{}, path []string) []Difference {
+	var diffs []Difference
+	for i := 0; i < len(from) || i < len(to); i++ {
+		indexPath := appendToken(path, strconv.Itoa(i))
+		switch {
+		case i >= len(from):
+			diffs = append(diffs, c.difference(Added, indexPath, nil, to[i])...)
+		case i >= len(to):
+			diffs = append(diffs, c.difference(Removed, indexPath, from[i], nil)...)
+		default:
+			diffs = append(diffs, c.diff(from[i], to[i], indexPath)...)
+		}
+	}
+	return diffs
+}
+
+func (c *diffConfig) diffUnorderedLists(from, to []interface{}, path []string) []Difference {
+	matched := make([]bool, len(from))
+	var added []Difference
+	for i, toElem := range to {
+		indexPath := appendToken(path, strconv.Itoa(i))
+		found := false
+		for j, fromElem := range from {
+			if !matched[j] && len(c.diff(fromElem, toElem, indexPath)) == 0 {
+				matched[j] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			added = append(added, c.difference(Added, indexPath, nil, toElem)...)
+		}
+	}
+
+	var diffs []Difference
+	for j, fromElem := range from {
+		if !matched[j] {
+			diffs = append(diffs, c.difference(Removed, appendToken(path, strconv.Itoa(j)), fromElem, nil)...)
+		}
+	}
+	return append(diffs, added...)
+}
+
+// difference records a single Difference at `path`, unless that path is
+// ignored. It returns a slice so that callers can append it directly.
+func (c *diffConfig) difference(typ ChangeType, path []string, from, to interface{}) []Difference {
+	if c.matchesAny(c.ignored, path) {
+		return nil
+	}
+	return []Difference{{
+		Pointer: JoinPointer(path),
+		Type:    typ,
+		From:    Data{data: from},
+		To:      Data{data: to},
+	}}
+}
+
+// Contains reports whether `part` is a structural subset of `whole`. Objects
+// in `whole` may have keys which aren't in `part`, and lists in `whole` may
+// have elements which aren't in `part`, but everything in `part` must be in
+// `whole`. List elements must appear in the same order, unless the list is at
+// a pointer given to UnorderedListsAt, in which case each element of `part`
+// must match a different element of `whole`, in any order. Pointers given to
+// IgnoreAt aren't checked.
+//
+// If `part` isn't contained in `whole`, Contains also returns the first
+// Difference it finds. Its Pointer points into `part`, From is the value in
+// `part` and To is the value in `whole`. The Type is Removed if `whole` has
+// nothing to match the value in `part`, and Changed otherwise.
+func Contains(whole, part Data, opts ...DiffOption) (bool, Difference, error) {
+	config := &diffConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+	if config.patternErr != nil {
+		return false, Difference{}, config.patternErr
+	}
+	mismatch := config.subset(part.data, whole.data, nil)
+	if len(mismatch) == 0 {
+		return true, Difference{}, nil
+	}
+	return false, mismatch[0], nil
+}
+
+// subset returns the first place where `part` isn't contained in `whole`, or
+// nothing if it is.
+func (c *diffConfig) subset(part, whole interface{}, path []string) []Difference {
+	if c.matchesAny(c.ignored, path) {
+		return nil
+	}
+	switch p := part.(type) {
+	case map[string]interface{}:
+		if w, ok := whole.(map[string]interface{}); ok {
+			for _, key := range sortedKeys(p) {
+				keyPath := appendToken(path, key)
+				wholeVal, ok := w[key]
+				if !ok {
+					if mismatch := c.difference(Removed, keyPath, p[key], nil); len(mismatch) > 0 {
+						return mismatch
+					}
+					continue
+				}
+				if mismatch := c.subset(p[key], wholeVal, keyPath); len(mismatch) > 0 {
+					return mismatch
+				}
+			}
+			return nil
+		}
+	case []interface{}:
+		if w, ok := whole.([]interface{}); ok {
+			return c.subsetList(p, w, path)
+		}
+	}
+	if reflect.DeepEqual(part, whole) {
+		return nil
+	}
+	return c.difference(Changed, path, part, whole)
+}
+
+func (c *diffConfig) subsetList(part, whole []interface{}, path []string) []Difference {
+	if c.matchesAny(c.unordered, path) {
+		return c.subsetUnorderedList(part, whole, path)
+	}
+	next := 0
+	for i, partElem := range part {
+		indexPath := appendToken(path, strconv.Itoa(i))
+		if c.matchesAny(c.ignored, indexPath) {
+			continue
+		}
+		found := false
+		for j := next; j < len(whole); j++ {
+			if len(c.subset(partElem, whole[j], indexPath)) == 0 {
+				next = j + 1
+				found = true
+				break
+			}
+		}
+		if !found {
+			// The element at the same index is the most likely counterpart,
+			// so describe how that one differs.
+			if i < len(whole) {
+				if mismatch := c.subset(partElem, whole[i], indexPath); len(mismatch) > 0 {
+					return mismatch
+				}
+			}
+			return c.difference(Removed, indexPath, partElem, nil)
+		}
+	}
+	return nil
+}
+
+// subsetUnorderedList pairs each element of `part` with a different element
+// of `whole` which contains it. Taking the first element which fits can use
+// up one which a later element needed, so it looks for augmenting paths to
+// find the largest pairing instead.
+func (c *diffConfig) subsetUnorderedList(part, whole []interface{}, path []string) []Difference {
+	fits := make([][]bool, len(part))
+	for i, partElem := range part {
+		fits[i] = make([]bool, len(whole))
+		for j, wholeElem := range whole {
+			fits[i][j] = len(c.subset(partElem, wholeElem, appendToken(path, strconv.Itoa(i)))) == 0
+		}
+	}
+	pairedWith := make([]int, len(whole))
+	for j := range pairedWith {
+		pairedWith[j] = -1
+	}
+	var augment func(i int, seen []bool) bool
+	augment = func(i int, seen []bool) bool {
+		for j := range whole {
+			if !fits[i][j] || seen[j] {
+				continue
+			}
+			seen[j] = true
+			if pairedWith[j] == -1 || augment(pairedWith[j], seen) {
+				pairedWith[j] = i
+				return true
+			}
+		}
+		return false
+	}
+	for i, partElem := range part {
+		indexPath := appendToken(path, strconv.Itoa(i))
+		if c.matchesAny(c.ignored, indexPath) {
+			continue
+		}
+		if !augment(i, make([]bool, len(whole))) {
+			return c.difference(Removed, indexPath, partElem, nil)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package unstructured_test
+
+import (
+	"github.com/totherme/unstructured"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Diff", func() {
+	var from unstructured.Data
+
+	diff := func(to string, opts ...unstructured.DiffOption) []unstructured.Difference {
+		diffs, err := unstructured.Diff(from, mustParseYAML(to), opts...)
+		Expect(err).NotTo(HaveOccurred())
+		return diffs
+	}
+
+	difference := func(pointer string, typ unstructured.ChangeType, from, to string) unstructured.Difference {
+		return unstructured.Difference{Pointer: pointer, Type: typ, From: mustParseYAML(from), To: mustParseYAML(to)}
+	}
+
+	BeforeEach(func() {
+		from = mustParseYAML(`
+name: my-deployment
+tags: [a, b]
+update: {canaries: 1, max_in_flight: 2}
+instance_groups:
+- name: web
+  instances: 2
+`)
+	})
+
+	It("finds nothing when the documents are equal", func() {
+		Expect(diff(`
+instance_groups: [{instances: 2, name: web}]
+name: my-deployment
+tags: [a, b]
+update: {max_in_flight: 2, canaries: 1}
+`)).To(BeEmpty())
+	})
+
+	It("reports every change, addition and removal in document order", func() {
+		Expect(diff(`
+name: production
+tags: [a, c, d]
+update: {canaries: 1, serial: true}
+instance_groups:
+- name: web
+  instances: {min: 1}
+`)).To(Equal([]unstructured.Difference{
+			difference("/instance_groups/0/instances", unstructured.Changed, "2", "{min: 1}"),
+			difference("/name", unstructured.Changed, "my-deployment", "production"),
+			difference("/tags/1", unstructured.Changed, "b", "c"),
+			difference("/tags/2", unstructured.Added, "null", "d"),
+			difference("/update/max_in_flight", unstructured.Removed, "2", "null"),
+			difference("/update/serial", unstructured.Added, "null", "true"),
+		}))
+	})
+
+	It("reports a change at the root of different documents", func() {
+		Expect(diff(`[]`)).To(Equal([]unstructured.Difference{
+			{Pointer: "", Type: unstructured.Changed, From: from, To: mustParseYAML(`[]`)},
+		}))
+	})
+
+	It("can ignore pointers", func() {
+		Expect(diff(`
+name: production
+tags: [a, b]
+update: {canaries: 5, max_in_flight: 2, serial: true}
+instance_groups: [{name: web, instances: 3}]
+`, unstructured.IgnoreAt("/name"), unstructured.IgnoreAt("/update/**"), unstructured.IgnoreAt("/instance_groups/*/instances"))).To(BeEmpty())
+	})
+
+	It("can compare lists as multisets", func() {
+		Expect(diff(`
+name: my-deployment
+tags: [b, a]
+update: {canaries: 1, max_in_flight: 2}
+instance_groups: [{name: web, instances: 2}]
+`, unstructured.UnorderedListsAt("/tags"))).To(BeEmpty())
+
+		Expect(diff(`
+name: my-deployment
+tags: [c, a, a]
+update: {canaries: 1, max_in_flight: 2}
+instance_groups: [{name: web, instances: 2}]
+`, unstructured.UnorderedListsAt("/tags"))).To(Equal([]unstructured.Difference{
+			difference("/tags/1", unstructured.Removed, "b", "null"),
+			difference("/tags/0", unstructured.Added, "null", "c"),
+			difference("/tags/2", unstructured.Added, "null", "a"),
+		}))
+	})
+
+	It("describes differences as test failures", func() {
+		Expect(difference("/a", unstructured.Changed, "{b: 1}", "[c]").String()).To(Equal(`'/a': expected {"b":1}, got ["c"]`))
+		Expect(difference("/a", unstructured.Added, "null", "x").String()).To(Equal(`'/a': unexpected "x"`))
+		Expect(difference("/a", unstructured.Removed, "7", "null").String()).To(Equal(`'/a': expected 7, but it was missing`))
+	})
+
+	It("returns an error for invalid patterns", func() {
+		_, err := unstructured.Diff(from, from, unstructured.IgnoreAt("name"))
+		Expect(err).To(MatchError(ContainSubstring("Invalid pattern 'name'")))
+	})
+
+	Describe("Contains", func() {
+		contains := func(part string, opts ...unstructured.DiffOption) (bool, unstructured.Difference) {
+			ok, mismatch, err := unstructured.Contains(from, mustParseYAML(part), opts...)
+			Expect(err).NotTo(HaveOccurred())
+			return ok, mismatch
+		}
+
+		It("accepts structural subsets", func() {
+			ok, _ := contains(`{update: {canaries: 1}, tags: [b], instance_groups: [{name: web}]}`)
+			Expect(ok).To(BeTrue())
+			ok, _ = contains(`{}`)
+			Expect(ok).To(BeTrue())
+		})
+
+		It("reports the first missing value", func() {
+			ok, mismatch := contains(`{update: {serial: true}}`)
+			Expect(ok).To(BeFalse())
+			Expect(mismatch).To(Equal(difference("/update/serial", unstructured.Removed, "true", "null")))
+		})
+
+		It("reports the first mismatched value", func() {
+			ok, mismatch := contains(`{instance_groups: [{instances: 3}]}`)
+			Expect(ok).To(BeFalse())
+			Expect(mismatch).To(Equal(difference("/instance_groups/0/instances", unstructured.Changed, "3", "2")))
+		})
+
+		It("requires list elements in order, unless told otherwise", func() {
+			ok, mismatch := contains(`{tags: [b, a]}`)
+			Expect(ok).To(BeFalse())
+			Expect(mismatch).To(Equal(difference("/tags/1", unstructured.Changed, "a", "b")))
+
+			ok, _ = contains(`{tags: [b, a]}`, unstructured.UnorderedListsAt("/tags"))
+			Expect(ok).To(BeTrue())
+		})
+
+		It("matches each element at most once", func() {
+			ok, _ := contains(`{tags: [a, a]}`, unstructured.UnorderedListsAt("/tags"))
+			Expect(ok).To(BeFalse())
+		})
+
+		It("finds a pairing of unordered elements even when the first fit is the wrong one", func() {
+			ok, _, err := unstructured.Contains(
+				mustParseYAML(`[{a: 1, b: 2}, {a: 1}]`),
+				mustParseYAML(`[{a: 1}, {a: 1, b: 2}]`),
+				unstructured.UnorderedListsAt(""))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+		})
+
+		It("skips ignored pointers", func() {
+			ok, _ := contains(`{name: other, update: {canaries: 1}}`, unstructured.IgnoreAt("/name"))
+			Expect(ok).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,110 @@
+package unstructured
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+)
+
+// MatchPointerEquals returns an ElementMatcher which matches elements with
+// the value `val` at pointer `p`. `val` is compared as json, so
+// `MatchPointerEquals("/instances", 3)` matches an element whose instances
+// field was parsed as the number 3.
+func MatchPointerEquals(p string, val interface{}) ElementMatcher {
+	want, err := normalize(val)
+	if err != nil {
+		return func(Data) bool { return false }
+	}
+	return func(elem Data) bool {
+		got, ok := valueAt(elem, p)
+		return ok && reflect.DeepEqual(got, want)
+	}
+}
+
+// MatchType returns an ElementMatcher which matches elements of type `typ`.
+// Valid values of `typ` are the same as for IsOfType.
+func MatchType(typ Kind) ElementMatcher {
+	return func(elem Data) bool {
+		return elem.Type() == typ
+	}
+}
+
+// MatchHasKey returns an ElementMatcher which matches objects containing
+// `key`.
+func MatchHasKey(key string) ElementMatcher {
+	return func(elem Data) bool {
+		return elem.IsOb() && elem.HasKey(key)
+	}
+}
+
+// MatchRegexp returns an ElementMatcher which matches elements with a string
+// matching `re` at pointer `p`.
+func MatchRegexp(p string, re *regexp.Regexp) ElementMatcher {
+	return func(elem Data) bool {
+		got, ok := valueAt(elem, p)
+		if !ok {
+			return false
+		}
+		s, isString := got.(string)
+		return isString && re.MatchString(s)
+	}
+}
+
+// And returns an ElementMatcher which matches elements matched by all of
+// `matchers`.
+func And(matchers ...ElementMatcher) ElementMatcher {
+	return func(elem Data) bool {
+		for _, match := range matchers {
+			if !match(elem) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or returns an ElementMatcher which matches elements matched by any of
+// `matchers`.
+func Or(matchers ...ElementMatcher) ElementMatcher {
+	return func(elem Data) bool {
+		for _, match := range matchers {
+			if match(elem) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not returns an ElementMatcher which matches elements not matched by
+// `match`.
+func Not(match ElementMatcher) ElementMatcher {
+	return func(elem Data) bool {
+		return !match(elem)
+	}
+}
+
+// valueAt returns the raw value at pointer `p` in `d`, if there is one.
+func valueAt(d Data, p string) (interface{}, bool) {
+	tokens, err := SplitPointer(p)
+	if err != nil {
+		return nil, false
+	}
+	val, found, err := getByTokens(d.data, tokens)
+	return val, found && err == nil
+}
+
+// normalize converts a go value into the form it would take if it were
+// parsed from json, so that it can be compared with parsed data.
+func normalize(val interface{}) (interface{}, error) {
+	if d, ok := val.(Data); ok {
+		return d.data, nil
+	}
+	encoded, err := json.Marshal(val)
+	if err != nil {
+		return nil, err
+	}
+	var normalized interface{}
+	err = json.Unmarshal(encoded, &normalized)
+	return normalized, err
+}
@@ -0,0 +1,111 @@
+package unstructured_test
+
+import (
+	"regexp"
+
+	"github.com/totherme/unstructured"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ElementMatchers", func() {
+	var groups unstructured.Data
+
+	names := func(list []unstructured.Data) []string {
+		var result []string
+		for _, elem := range list {
+			result = append(result, elem.F("name").UnsafeStringValue())
+		}
+		return result
+	}
+
+	BeforeEach(func() {
+		var err error
+		groups, err = unstructured.ParseYAML(`
+- {name: web, type: frontend, instances: 3, azs: [z1, z2]}
+- {name: db, type: backend, instances: 1}
+- {name: worker, type: backend, instances: 10, azs: [z1]}
+- {name: cache, type: backend}
+- not an instance group
+`)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Describe("MatchPointerEquals", func() {
+		It("matches elements with the given value at the pointer", func() {
+			web, ok := groups.FindElem(unstructured.MatchPointerEquals("/name", "web"))
+			Expect(ok).To(BeTrue())
+			Expect(web.F("type").UnsafeStringValue()).To(Equal("frontend"))
+		})
+
+		It("compares go values as json", func() {
+			Expect(names(groups.FindAll(unstructured.MatchPointerEquals("/instances", 10)))).To(Equal([]string{"worker"}))
+			Expect(names(groups.FindAll(unstructured.MatchPointerEquals("/azs", []string{"z1", "z2"})))).To(Equal([]string{"web"}))
+		})
+
+		It("compares Data values as json", func() {
+			Expect(names(groups.FindAll(unstructured.MatchPointerEquals("/azs", groups.UnsafeListValue()[2].F("azs"))))).
+				To(Equal([]string{"worker"}))
+		})
+
+		It("doesn't match elements without the pointer", func() {
+			Expect(groups.FindAll(unstructured.MatchPointerEquals("/size", "large"))).To(BeEmpty())
+		})
+
+		It("doesn't match anything if the pointer is invalid", func() {
+			Expect(groups.FindAll(unstructured.MatchPointerEquals("name", "web"))).To(BeEmpty())
+		})
+	})
+
+	Describe("MatchType", func() {
+		It("matches elements of the given type", func() {
+			Expect(groups.FindAll(unstructured.MatchType(unstructured.DataOb))).To(HaveLen(4))
+			Expect(groups.FindAll(unstructured.MatchType(unstructured.DataString))).To(HaveLen(1))
+		})
+	})
+
+	Describe("MatchHasKey", func() {
+		It("matches objects with the given key", func() {
+			Expect(names(groups.FindAll(unstructured.MatchHasKey("azs")))).To(Equal([]string{"web", "worker"}))
+		})
+	})
+
+	Describe("MatchRegexp", func() {
+		It("matches elements with a matching string at the pointer", func() {
+			Expect(names(groups.FindAll(unstructured.MatchRegexp("/name", regexp.MustCompile("^w"))))).To(Equal([]string{"web", "worker"}))
+		})
+
+		It("doesn't match non-strings", func() {
+			Expect(groups.FindAll(unstructured.MatchRegexp("/instances", regexp.MustCompile(".*")))).To(BeEmpty())
+		})
+	})
+
+	Describe("combinators", func() {
+		It("can require all matchers to match", func() {
+			Expect(names(groups.FindAll(unstructured.And(
+				unstructured.MatchPointerEquals("/type", "backend"),
+				unstructured.MatchHasKey("instances"),
+			)))).To(Equal([]string{"db", "worker"}))
+		})
+
+		It("can require any matcher to match", func() {
+			Expect(names(groups.FindAll(unstructured.Or(
+				unstructured.MatchPointerEquals("/name", "web"),
+				unstructured.MatchPointerEquals("/name", "cache"),
+			)))).To(Equal([]string{"web", "cache"}))
+		})
+
+		It("can negate a matcher", func() {
+			Expect(names(groups.FindAll(unstructured.And(
+				unstructured.MatchType(unstructured.DataOb),
+				unstructured.Not(unstructured.MatchHasKey("azs")),
+			)))).To(Equal([]string{"db", "cache"}))
+		})
+
+		It("treats empty combinations as true for And and false for Or", func() {
+			Expect(groups.FindAll(unstructured.And())).To(HaveLen(5))
+			Expect(groups.FindAll(unstructured.Or())).To(BeEmpty())
+		})
+	})
+})
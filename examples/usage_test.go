@@ -69,6 +69,14 @@ var _ = Describe("Some things you can test with gunstructured", func() {
 			Expect(json.F("employees").UnsafeListValue()).To(HaveLen(3))
 		})
 
+		It("contains three employees - matcher formulation", func() {
+			Expect(json).To(HaveDataAt("/employees", HaveDataLen(3)))
+		})
+
+		It("employs someone in engineering", func() {
+			Expect(json).To(HaveDataAt("/employees", ContainElementMatching(HaveValueAt("/department", Equal("engineering")))))
+		})
+
 		Describe("the first employee", func() {
 			It("is great at cooking", func() {
 				skill, err := json.GetByPointer("/employees/0/profile/special-skill")
@@ -82,6 +90,10 @@ var _ = Describe("Some things you can test with gunstructured", func() {
 			It("is great at cooking -- alternative formulation", func() {
 				Expect(json.F("employees").UnsafeListValue()[0].F("profile").F("special-skill").UnsafeStringValue()).To(Equal("szechuan cookery"))
 			})
+
+			It("is great at cooking -- matcher formulation", func() {
+				Expect(json).To(HaveValueAt("/employees/0/profile/special-skill", Equal("szechuan cookery")))
+			})
 		})
 	})
 })
@@ -0,0 +1,153 @@
+package unstructured
+
+import (
+	"sort"
+	"strconv"
+)
+
+const (
+	// GlobAny is a pointer pattern segment which matches exactly one segment
+	// of a pointer -- any key of an object, or any index of a list.
+	GlobAny = "*"
+	// GlobAnyDepth is a pointer pattern segment which matches zero or more
+	// segments of a pointer.
+	GlobAnyDepth = "**"
+)
+
+// GlobPointer returns every pointer into this Data struct which matches the
+// pointer pattern `pattern`, in document order. Object keys are visited in
+// sorted order, so the result is deterministic.
+//
+// A pointer pattern is a json pointer, some of whose segments may be `*`
+// (matching any single key or index) or `**` (matching any number of
+// segments, including none). For example, `/instance_groups/*/jobs/*/name`
+// matches the name of every job in every instance group. All other segments
+// use the usual escaping rules, so `/a~1b/*` matches every child of the key
+// `a/b`.
+//
+// For more information on json pointers, see https://tools.ietf.org/html/rfc6901
+func (j Data) GlobPointer(pattern string) ([]string, error) {
+	tokens, err := SplitPointer(pattern)
+	if err != nil {
+		return nil, err
+	}
+	var found [][]string
+	seen := map[string]bool{}
+	globPointer(j.data, nil, tokens, func(match []string) {
+		p := JoinPointer(match)
+		if !seen[p] {
+			seen[p] = true
+			found = append(found, match)
+		}
+	})
+	sort.SliceStable(found, func(a, b int) bool {
+		return tokensBefore(j.data, found[a], found[b])
+	})
+	matches := make([]string, len(found))
+	for i, match := range found {
+		matches[i] = JoinPointer(match)
+	}
+	return matches, nil
+}
+
+func globPointer(node interface{}, path []string, pattern []string, found func([]string)) {
+	if len(pattern) == 0 {
+		found(path)
+		return
+	}
+	head, rest := pattern[0], pattern[1:]
+	if head == GlobAnyDepth {
+		globPointer(node, path, rest, found)
+		forEachChild(node, func(token string, child interface{}) {
+			globPointer(child, appendToken(path, token), pattern, found)
+		})
+		return
+	}
+	forEachChild(node, func(token string, child interface{}) {
+		if head == GlobAny || head == token {
+			globPointer(child, appendToken(path, token), rest, found)
+		}
+	})
+}
+
+// forEachChild calls `f` with the reference token and value of each child of
+// `node`, in document order. Scalars have no children.
+func forEachChild(node interface{}, f func(token string, child interface{})) {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedKeys(n) {
+			f(key, n[key])
+		}
+	case []interface{}:
+		for i, child := range n {
+			f(strconv.Itoa(i), child)
+		}
+	}
+}
+
+// matchTokens returns true iff the path `tokens` is matched by the pointer
+// pattern `pattern`, as described in GlobPointer. Unlike GlobPointer, this
+// needs no document: it compares the paths segment by segment.
+func matchTokens(pattern, tokens []string) bool {
+	if len(pattern) == 0 {
+		return len(tokens) == 0
+	}
+	if pattern[0] == GlobAnyDepth {
+		for i := 0; i <= len(tokens); i++ {
+			if matchTokens(pattern[1:], tokens[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(tokens) == 0 {
+		return false
+	}
+	if pattern[0] != GlobAny && pattern[0] != tokens[0] {
+		return false
+	}
+	return matchTokens(pattern[1:], tokens[1:])
+}
+
+// tokensBefore returns true iff the path `a` comes before the path `b` in
+// document order, in the document `node`. List indexes are compared
+// numerically, so that `/10` comes after `/9`, while object keys are compared
+// as strings, so that the key "10" comes before the key "9".
+func tokensBefore(node interface{}, a, b []string) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] == b[i] {
+			continue
+		}
+		if parent, _, _ := getByTokens(node, a[:i]); isList(parent) {
+			ai, aErr := strconv.Atoi(a[i])
+			bi, bErr := strconv.Atoi(b[i])
+			if aErr == nil && bErr == nil {
+				return ai < bi
+			}
+		}
+		return a[i] < b[i]
+	}
+	return len(a) < len(b)
+}
+
+func isList(node interface{}) bool {
+	_, ok := node.([]interface{})
+	return ok
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// appendToken returns a new path, leaving `path` untouched so that sibling
+// branches of a traversal can't overwrite each other's tokens.
+func appendToken(path []string, token string) []string {
+	newPath := make([]string, len(path), len(path)+1)
+	copy(newPath, path)
+	return append(newPath, token)
+}
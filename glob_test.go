@@ -0,0 +1,115 @@
+package unstructured_test
+
+import (
+	"github.com/totherme/unstructured"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GlobPointer", func() {
+	var manifest unstructured.Data
+
+	BeforeEach(func() {
+		var err error
+		manifest, err = unstructured.ParseYAML(`
+name: my-deployment
+instance_groups:
+- name: web
+  jobs:
+  - name: nginx
+  - name: route_registrar
+- name: db
+  jobs:
+  - name: postgres
+weird~keys:
+  a/b: slashed
+`)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("returns the pointer itself when the pattern has no wildcards", func() {
+		Expect(manifest.GlobPointer("/instance_groups/0/name")).To(Equal([]string{"/instance_groups/0/name"}))
+	})
+
+	It("returns no pointers when a plain pattern doesn't exist", func() {
+		Expect(manifest.GlobPointer("/instance_groups/7/name")).To(BeEmpty())
+	})
+
+	It("matches any single segment with *", func() {
+		Expect(manifest.GlobPointer("/instance_groups/*/jobs/*/name")).To(Equal([]string{
+			"/instance_groups/0/jobs/0/name",
+			"/instance_groups/0/jobs/1/name",
+			"/instance_groups/1/jobs/0/name",
+		}))
+	})
+
+	It("visits object keys in sorted order", func() {
+		Expect(manifest.GlobPointer("/*")).To(Equal([]string{
+			"/instance_groups",
+			"/name",
+			"/weird~0keys",
+		}))
+	})
+
+	It("compares list indexes as numbers, but object keys as strings", func() {
+		data, err := unstructured.ParseYAML(`{a: {"10": x, "9": y}, b: [0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10]}`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(data.GlobPointer("/a/*")).To(Equal([]string{"/a/10", "/a/9"}))
+		pointers, err := data.GlobPointer("/b/*")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pointers[9:]).To(Equal([]string{"/b/9", "/b/10"}))
+	})
+
+	It("matches any number of segments with **", func() {
+		Expect(manifest.GlobPointer("/**/name")).To(Equal([]string{
+			"/instance_groups/0/jobs/0/name",
+			"/instance_groups/0/jobs/1/name",
+			"/instance_groups/0/name",
+			"/instance_groups/1/jobs/0/name",
+			"/instance_groups/1/name",
+			"/name",
+		}))
+	})
+
+	It("lets ** match zero segments", func() {
+		Expect(manifest.GlobPointer("/instance_groups/0/name/**")).To(Equal([]string{"/instance_groups/0/name"}))
+	})
+
+	It("doesn't return the same pointer twice", func() {
+		Expect(manifest.GlobPointer("/**/**/jobs")).To(Equal([]string{
+			"/instance_groups/0/jobs",
+			"/instance_groups/1/jobs",
+		}))
+	})
+
+	It("uses the usual pointer escaping rules", func() {
+		Expect(manifest.GlobPointer("/weird~0keys/a~1b")).To(Equal([]string{"/weird~0keys/a~1b"}))
+		Expect(manifest.GlobPointer("/weird~0keys/*")).To(Equal([]string{"/weird~0keys/a~1b"}))
+	})
+
+	It("returns pointers which GetByPointer can follow", func() {
+		pointers, err := manifest.GlobPointer("/instance_groups/*/jobs/*/name")
+		Expect(err).NotTo(HaveOccurred())
+		var names []string
+		for _, p := range pointers {
+			name, err := manifest.GetByPointer(p)
+			Expect(err).NotTo(HaveOccurred())
+			names = append(names, name.UnsafeStringValue())
+		}
+		Expect(names).To(Equal([]string{"nginx", "route_registrar", "postgres"}))
+	})
+
+	Context("when the pattern is empty", func() {
+		It("matches the whole document", func() {
+			Expect(manifest.GlobPointer("")).To(Equal([]string{""}))
+		})
+	})
+
+	Context("when the pattern is invalid", func() {
+		It("returns a helpful error message", func() {
+			_, err := manifest.GlobPointer("instance_groups/*")
+			Expect(err).To(MatchError(ContainSubstring("JSON pointer must be empty or start with a \"/\"")))
+		})
+	})
+})
@@ -8,7 +8,6 @@ require (
 	github.com/ghodss/yaml v1.0.0
 	github.com/onsi/ginkgo/v2 v2.20.2
 	github.com/onsi/gomega v1.34.1
-	github.com/xeipuuv/gojsonpointer v0.0.0-20190905194746-02993c407bfb
 	gopkg.in/yaml.v3 v3.0.1
 )
 
@@ -0,0 +1,130 @@
+package gunstructured
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sync"
+
+	"github.com/totherme/unstructured"
+)
+
+// actualCache remembers what a matcher read from an io.Reader which can't be
+// rewound, such as an http.Response's Body. Gomega passes the same actual
+// value to Match and then to FailureMessage, and the second time there would
+// be nothing left to read. Each matcher has its own cache, so the body is
+// dropped along with the matcher.
+type actualCache struct {
+	mu     sync.Mutex
+	reader io.Reader
+	body   []byte
+}
+
+func newActualCache() *actualCache {
+	return &actualCache{}
+}
+
+// toData turns the actual value given to a matcher into unstructured.Data.
+// As well as Data itself, every matcher in this package accepts:
+//
+//   - a string, []byte or json.RawMessage, which is parsed as yaml (and so
+//     also as json)
+//   - a map[string]interface{} or []interface{}, which is converted as if it
+//     had been marshalled to json and parsed
+//   - an *http.Response, whose body is parsed, and then replaced so that
+//     other code can read it again
+//   - any other io.Reader, such as an http.Response's Body, which is read to
+//     the end and parsed. If it is also an io.Seeker, it is then rewound, so
+//     that it can be read again
+//
+// Note that a string is parsed as a document, so `"42"` is a number, and
+// `"hello"` is a string.
+func toData(actual interface{}) (unstructured.Data, error) {
+	return (*actualCache)(nil).toData(actual)
+}
+
+// toData is like the function toData, but remembers the contents of a reader
+// which can't be rewound, so that the matcher can read it again. A nil cache
+// remembers nothing.
+func (c *actualCache) toData(actual interface{}) (unstructured.Data, error) {
+	switch a := actual.(type) {
+	case unstructured.Data:
+		return a, nil
+	case string:
+		return parseActual([]byte(a))
+	case []byte:
+		return parseActual(a)
+	case json.RawMessage:
+		return parseActual(a)
+	case map[string]interface{}, []interface{}:
+		encoded, err := json.Marshal(a)
+		if err != nil {
+			return unstructured.Data{}, fmt.Errorf("couldn't convert the %T to Data: %s", actual, err)
+		}
+		return unstructured.ParseJSON(string(encoded))
+	case *http.Response:
+		if a == nil || a.Body == nil {
+			return unstructured.Data{}, fmt.Errorf("the http.Response has no body")
+		}
+		body, err := io.ReadAll(a.Body)
+		a.Body.Close()
+		a.Body = io.NopCloser(bytes.NewReader(body))
+		if err != nil {
+			return unstructured.Data{}, fmt.Errorf("couldn't read the http.Response body: %s", err)
+		}
+		return parseActual(body)
+	case io.ReadSeeker:
+		start, err := a.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return unstructured.Data{}, fmt.Errorf("couldn't read from the %T: %s", actual, err)
+		}
+		body, err := io.ReadAll(a)
+		if err != nil {
+			return unstructured.Data{}, fmt.Errorf("couldn't read from the %T: %s", actual, err)
+		}
+		if _, err := a.Seek(start, io.SeekStart); err != nil {
+			return unstructured.Data{}, fmt.Errorf("couldn't rewind the %T: %s", actual, err)
+		}
+		return parseActual(body)
+	case io.Reader:
+		body, err := c.read(a)
+		if err != nil {
+			return unstructured.Data{}, err
+		}
+		return parseActual(body)
+	default:
+		return unstructured.Data{}, fmt.Errorf("%T is not a Data object. Have you done unstructured.Parse[JSON|YAML](...)?", actual)
+	}
+}
+
+func parseActual(raw []byte) (unstructured.Data, error) {
+	data, err := unstructured.ParseYAML(string(raw))
+	if err != nil {
+		return unstructured.Data{}, fmt.Errorf("couldn't parse the actual value as json or yaml: %s", err)
+	}
+	return data, nil
+}
+
+func (c *actualCache) read(reader io.Reader) ([]byte, error) {
+	if c != nil {
+		if !reflect.TypeOf(reader).Comparable() {
+			return nil, fmt.Errorf("can't read from a %T more than once", reader)
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.reader == reader {
+			return c.body, nil
+		}
+	}
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read from the %T: %s", reader, err)
+	}
+	if c != nil {
+		c.reader, c.body = reader, body
+	}
+	return body, nil
+}
@@ -0,0 +1,72 @@
+package gunstructured_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/totherme/unstructured/gunstructured"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("The values the matchers accept", func() {
+	const rawjson = `{"id": 7, "name": "fred", "tags": ["a"]}`
+
+	DescribeTable("parse or convert the actual value", func(actual interface{}) {
+		Expect(actual).To(gunstructured.HaveJSONPointer("/id"))
+		Expect(actual).To(gunstructured.HaveValueAt("/name", Equal("fred")))
+		Expect(actual).To(gunstructured.MatchYAMLData("{id: 7, name: fred, tags: [a]}"))
+		Expect(actual).To(gunstructured.BeAnObject())
+	},
+		Entry("a json string", rawjson),
+		Entry("a yaml string", "{id: 7, name: fred, tags: [a]}"),
+		Entry("bytes", []byte(rawjson)),
+		Entry("a json.RawMessage", json.RawMessage(rawjson)),
+		Entry("a map", map[string]interface{}{"id": 7, "name": "fred", "tags": []string{"a"}}),
+		Entry("a reader", strings.NewReader(rawjson)),
+		Entry("an http.Response", &http.Response{Body: io.NopCloser(strings.NewReader(rawjson))}),
+	)
+
+	It("leaves an http.Response's body readable", func() {
+		resp := &http.Response{Body: io.NopCloser(strings.NewReader(rawjson))}
+		Expect(resp).To(gunstructured.HaveJSONKey("id"))
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(Equal(rawjson))
+	})
+
+	It("can report failures on a reader which has already been read", func() {
+		reader := strings.NewReader(rawjson)
+		matcher := gunstructured.HaveValueAt("/name", Equal("alice"))
+		Expect(matcher.Match(reader)).To(BeFalse())
+		Expect(matcher.FailureMessage(reader)).To(HavePrefix(`at pointer '/name', found "fred"`))
+	})
+
+	It("can report failures on a reader which can't be rewound", func() {
+		reader := io.MultiReader(strings.NewReader(rawjson))
+		matcher := gunstructured.HaveValueAt("/name", Equal("alice"))
+		Expect(matcher.Match(reader)).To(BeFalse())
+		Expect(matcher.FailureMessage(reader)).To(HavePrefix(`at pointer '/name', found "fred"`))
+	})
+
+	It("rewinds readers which can be rewound", func() {
+		reader := strings.NewReader(rawjson)
+		Expect(reader).To(gunstructured.HaveJSONKey("id"))
+		body, err := io.ReadAll(reader)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(Equal(rawjson))
+	})
+
+	It("parses strings as documents", func() {
+		Expect("42").To(gunstructured.BeANum())
+		Expect("hello").To(gunstructured.BeAString())
+	})
+
+	It("returns an error when the actual value doesn't parse", func() {
+		_, err := gunstructured.HaveJSONKey("id").Match(`{"id": `)
+		Expect(err).To(MatchError(ContainSubstring("couldn't parse the actual value as json or yaml")))
+	})
+})
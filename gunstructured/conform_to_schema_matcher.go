@@ -0,0 +1,76 @@
+package gunstructured
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/totherme/unstructured/jsonschema"
+)
+
+// ConformToSchemaMatcher is a gomega matcher which tests if a given value
+// represents a document conforming to a JSON Schema.
+type ConformToSchemaMatcher struct {
+	schema *jsonschema.Schema
+	err    error
+	cache  *actualCache
+}
+
+// ConformToSchema returns a gomega matcher which tests if a given value
+// represents a document which conforms to `schema`, as checked by the
+// jsonschema package. `schema` may be an unstructured.Data, or anything else
+// the matchers in this package accept as an actual value, such as a yaml or
+// json string. When the match fails, the failure message lists every
+// violation, with the pointer at which it was found.
+func ConformToSchema(schema interface{}) ConformToSchemaMatcher {
+	data, err := toData(schema)
+	if err != nil {
+		return ConformToSchemaMatcher{err: fmt.Errorf("couldn't parse the schema: %s", err)}
+	}
+	compiled, err := jsonschema.Compile(data)
+	if err != nil {
+		return ConformToSchemaMatcher{err: err}
+	}
+	return ConformToSchemaMatcher{schema: compiled, cache: newActualCache()}
+}
+
+// Match is the gomega function that actually checks if the given value
+// conforms to the schema.
+func (m ConformToSchemaMatcher) Match(actual interface{}) (bool, error) {
+	violations, err := m.validate(actual)
+	if err != nil {
+		return false, err
+	}
+	return len(violations) == 0, nil
+}
+
+// FailureMessage constructs a hopefully-helpful error message in the case that
+// the given value doesn't conform to the schema, listing every violation.
+func (m ConformToSchemaMatcher) FailureMessage(actual interface{}) (message string) {
+	violations, err := m.validate(actual)
+	if err != nil {
+		return err.Error()
+	}
+	lines := make([]string, len(violations))
+	for i, violation := range violations {
+		lines[i] = "  " + violation.Error()
+	}
+	return fmt.Sprintf("expected the Data to conform to the schema, but found %d violation(s):\n%s",
+		len(violations), strings.Join(lines, "\n"))
+}
+
+// NegatedFailureMessage constructs a hopefully-helpful error message in the
+// case that the given value unexpectedly conforms to the schema.
+func (m ConformToSchemaMatcher) NegatedFailureMessage(actual interface{}) (message string) {
+	return fmt.Sprintf("expected %s not to conform to the schema", truncateString(m.cache.describeActual(actual)))
+}
+
+func (m ConformToSchemaMatcher) validate(actual interface{}) ([]jsonschema.Violation, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	data, err := m.cache.toData(actual)
+	if err != nil {
+		return nil, err
+	}
+	return m.schema.Validate(data), nil
+}
@@ -0,0 +1,47 @@
+package gunstructured_test
+
+import (
+	"github.com/totherme/unstructured"
+	"github.com/totherme/unstructured/gunstructured"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ConformToSchemaMatcher", func() {
+	const schema = `
+type: object
+required: [name, life]
+properties:
+  name: {type: string}
+  life: {type: integer, minimum: 0}
+  othernames: {type: array, items: {type: string}}
+`
+
+	It("matches documents which conform to the schema", func() {
+		Expect(`{"name": "fred", "life": 42, "othernames": ["alice"]}`).To(gunstructured.ConformToSchema(schema))
+	})
+
+	It("accepts the schema as Data", func() {
+		schemaData, err := unstructured.ParseYAML(schema)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(`{"name": "fred", "life": 42}`).To(gunstructured.ConformToSchema(schemaData))
+		Expect(`{"name": "fred", "life": -1}`).NotTo(gunstructured.ConformToSchema(schemaData))
+	})
+
+	It("lists every violation in the failure message", func() {
+		message := gunstructured.ConformToSchema(schema).FailureMessage(`{"life": 4.5, "othernames": ["alice", 7]}`)
+		Expect(message).To(HavePrefix("expected the Data to conform to the schema, but found 3 violation(s):\n"))
+		Expect(message).To(ContainSubstring("at '': "))
+		Expect(message).To(ContainSubstring("at '/life': "))
+		Expect(message).To(ContainSubstring("at '/othernames/1': "))
+	})
+
+	It("returns an error when the schema is invalid", func() {
+		_, err := gunstructured.ConformToSchema(`{type: object, properties: {name: 7}}`).Match(`{}`)
+		Expect(err).To(MatchError(ContainSubstring("Invalid schema at '/properties/name'")))
+
+		_, err = gunstructured.ConformToSchema(`{type: [`).Match(`{}`)
+		Expect(err).To(MatchError(ContainSubstring("couldn't parse the schema")))
+	})
+})
@@ -0,0 +1,73 @@
+package gunstructured
+
+import (
+	"fmt"
+
+	"github.com/totherme/unstructured"
+)
+
+// ContainDataMatcher is a gomega matcher which tests if a given value
+// represents unstructured data containing some expected document.
+type ContainDataMatcher struct {
+	expected unstructured.Data
+	parseErr error
+	opts     []unstructured.DiffOption
+	cache    *actualCache
+}
+
+// ContainData returns a gomega matcher which tests if a given value represents
+// data which contains `expected`, as described in unstructured.Contains: the
+// actual data may have extra keys and list elements, but everything in
+// `expected` must be there. `expected` may be anything the matchers in this
+// package accept as an actual value, such as a yaml or json string. By
+// default list elements must appear in the same order, which
+// unstructured.UnorderedListsAt can relax:
+//
+//	Expect(response).To(ContainData(`{employees: [{name: Alex}]}`,
+//		unstructured.UnorderedListsAt("/employees")))
+func ContainData(expected interface{}, opts ...unstructured.DiffOption) ContainDataMatcher {
+	m := ContainDataMatcher{opts: opts, cache: newActualCache()}
+	m.expected, m.parseErr = toData(expected)
+	return m
+}
+
+// MatchDataSubset is exactly like ContainData.
+func MatchDataSubset(expected interface{}, opts ...unstructured.DiffOption) ContainDataMatcher {
+	return ContainData(expected, opts...)
+}
+
+// Match is the gomega function that actually checks if the given value
+// represents data containing the expected document.
+func (m ContainDataMatcher) Match(actual interface{}) (bool, error) {
+	ok, _, err := m.contains(actual)
+	return ok, err
+}
+
+// FailureMessage constructs a hopefully-helpful error message in the case that
+// the given value doesn't contain the expected document, showing the first
+// pointer at which it doesn't.
+func (m ContainDataMatcher) FailureMessage(actual interface{}) (message string) {
+	_, mismatch, err := m.contains(actual)
+	if err != nil {
+		return err.Error()
+	}
+	return fmt.Sprintf("expected the Data to contain %s, but at %s",
+		truncateString(describe(m.expected)), mismatch)
+}
+
+// NegatedFailureMessage constructs a hopefully-helpful error message in the
+// case that the given value unexpectedly contains the expected document.
+func (m ContainDataMatcher) NegatedFailureMessage(actual interface{}) (message string) {
+	return fmt.Sprintf("expected the Data not to contain %s", truncateString(describe(m.expected)))
+}
+
+func (m ContainDataMatcher) contains(actual interface{}) (bool, unstructured.Difference, error) {
+	if m.parseErr != nil {
+		return false, unstructured.Difference{}, fmt.Errorf("couldn't parse the expected data: %s", m.parseErr)
+	}
+	data, err := m.cache.toData(actual)
+	if err != nil {
+		return false, unstructured.Difference{}, err
+	}
+	return unstructured.Contains(data, m.expected, m.opts...)
+}
@@ -0,0 +1,67 @@
+package gunstructured_test
+
+import (
+	"github.com/totherme/unstructured"
+	"github.com/totherme/unstructured/gunstructured"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ContainDataMatcher", func() {
+	var json unstructured.Data
+
+	BeforeEach(func() {
+		var err error
+		json, err = unstructured.ParseJSON(`{
+			"name": "fred",
+			"othernames": ["alice", "bob", "ezekiel"],
+			"things": {"more": "things", "count": 3}
+		}`)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Describe("Match", func() {
+		It("matches subsets given as Data or as strings", func() {
+			expected, err := unstructured.ParseJSON(`{"things": {"count": 3}}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(json).To(gunstructured.ContainData(expected))
+			Expect(json).To(gunstructured.ContainData(`{name: fred, othernames: [alice, ezekiel]}`))
+			Expect(json).To(gunstructured.MatchDataSubset(`{"things": {}}`))
+		})
+
+		It("requires list elements in order by default", func() {
+			Expect(json).NotTo(gunstructured.ContainData(`{othernames: [ezekiel, alice]}`))
+			Expect(json).To(gunstructured.ContainData(`{othernames: [ezekiel, alice]}`, unstructured.UnorderedListsAt("/othernames")))
+		})
+
+		It("doesn't match when something is missing or different", func() {
+			Expect(json).NotTo(gunstructured.ContainData(`{things: {count: 4}}`))
+			Expect(json).NotTo(gunstructured.ContainData(`{age: 42}`))
+		})
+
+		It("returns an error when the expected data is unusable", func() {
+			_, err := gunstructured.ContainData(`{name: [`).Match(json)
+			Expect(err).To(MatchError(ContainSubstring("couldn't parse the expected data")))
+			_, err = gunstructured.ContainData(42).Match(json)
+			Expect(err).To(MatchError(ContainSubstring("int is not a Data object")))
+		})
+
+		It("returns an error when given something it can't turn into Data", func() {
+			_, err := gunstructured.ContainData(`{}`).Match(42)
+			Expect(err).To(MatchError(ContainSubstring("not a Data object. Have you done unstructured.Parse[JSON|YAML](...)?")))
+		})
+	})
+
+	Describe("FailureMessage", func() {
+		It("reports the first mismatched pointer", func() {
+			Expect(gunstructured.ContainData(`{things: {count: 4, more: things}}`).FailureMessage(json)).
+				To(Equal(`expected the Data to contain {"things":{"count":4,"more":"things"}}, but at '/things/count': expected 4, got 3`))
+		})
+
+		It("reports the first missing pointer", func() {
+			Expect(gunstructured.ContainData(`{othernames: [zed]}`, unstructured.UnorderedListsAt("/othernames")).FailureMessage(json)).
+				To(Equal(`expected the Data to contain {"othernames":["zed"]}, but at '/othernames/0': expected "zed", but it was missing`))
+		})
+	})
+})
@@ -0,0 +1,160 @@
+package gunstructured
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/totherme/unstructured"
+)
+
+// UpdateGoldenEnv is the environment variable which makes MatchGoldenFile
+// rewrite golden files rather than compare against them.
+const UpdateGoldenEnv = "UPDATE_GOLDEN"
+
+// MatchGoldenFileMatcher is a gomega matcher which tests if a given value
+// represents the same data as a golden file.
+type MatchGoldenFileMatcher struct {
+	path  string
+	opts  []unstructured.DiffOption
+	cache *actualCache
+}
+
+// MatchGoldenFile returns a gomega matcher which tests if a given value
+// represents the same data as the file at `path`. Files ending in `.json` are
+// parsed as json, and files ending in `.yml` or `.yaml` as yaml. The
+// comparison is structural, so formatting and key order don't matter, and
+// the failure message lists every differing pointer, as for MatchData.
+// DiffOptions relax the comparison in the same way.
+//
+// If the environment variable UPDATE_GOLDEN is set to anything other than
+// "" or "0", the matcher writes the actual value to the file instead, and
+// always matches. So after checking that a change in the output is intended,
+// you can update your golden files with:
+//
+//	UPDATE_GOLDEN=1 go test ./...
+func MatchGoldenFile(path string, opts ...unstructured.DiffOption) MatchGoldenFileMatcher {
+	return MatchGoldenFileMatcher{path: path, opts: opts, cache: newActualCache()}
+}
+
+// Match is the gomega function that actually checks if the given value
+// represents the same data as the golden file, or updates the file.
+func (m MatchGoldenFileMatcher) Match(actual interface{}) (bool, error) {
+	if updateGolden() {
+		data, err := m.cache.toData(actual)
+		if err != nil {
+			return false, err
+		}
+		return true, m.write(data)
+	}
+	golden, err := m.read()
+	if err != nil {
+		return false, err
+	}
+	return m.matchData(golden).Match(actual)
+}
+
+// FailureMessage constructs a hopefully-helpful error message in the case that
+// the given value doesn't represent the same data as the golden file, listing
+// each difference.
+func (m MatchGoldenFileMatcher) FailureMessage(actual interface{}) (message string) {
+	golden, err := m.read()
+	if err != nil {
+		return err.Error()
+	}
+	return fmt.Sprintf("%s\nIf this change is intended, run the tests again with %s=1 to update '%s'.",
+		m.matchData(golden).FailureMessage(actual), UpdateGoldenEnv, m.path)
+}
+
+// NegatedFailureMessage constructs a hopefully-helpful error message in the
+// case that the given value unexpectedly represents the same data as the
+// golden file.
+func (m MatchGoldenFileMatcher) NegatedFailureMessage(actual interface{}) (message string) {
+	return fmt.Sprintf("expected the Data not to match the golden file '%s'", m.path)
+}
+
+// matchData returns a MatchDataMatcher for the golden data, which shares this
+// matcher's cache of the actual value.
+func (m MatchGoldenFileMatcher) matchData(golden unstructured.Data) MatchDataMatcher {
+	return MatchDataMatcher{expected: golden, opts: m.opts, cache: m.cache}
+}
+
+func updateGolden() bool {
+	value := os.Getenv(UpdateGoldenEnv)
+	return value != "" && value != "0"
+}
+
+func (m MatchGoldenFileMatcher) read() (unstructured.Data, error) {
+	raw, err := os.ReadFile(m.path)
+	if os.IsNotExist(err) {
+		return unstructured.Data{}, fmt.Errorf("the golden file '%s' doesn't exist. Run the tests with %s=1 to create it", m.path, UpdateGoldenEnv)
+	}
+	if err != nil {
+		return unstructured.Data{}, fmt.Errorf("couldn't read the golden file: %s", err)
+	}
+
+	var data unstructured.Data
+	switch m.format() {
+	case "json":
+		data, err = unstructured.ParseJSON(string(raw))
+	case "yaml":
+		data, err = unstructured.ParseYAML(string(raw))
+	default:
+		return unstructured.Data{}, m.formatError()
+	}
+	if err != nil {
+		return unstructured.Data{}, fmt.Errorf("couldn't parse the golden file '%s': %s", m.path, err)
+	}
+	return data, nil
+}
+
+func (m MatchGoldenFileMatcher) write(data unstructured.Data) error {
+	// Encode by hand, so that characters like `<` and `&` aren't escaped for
+	// HTML. The encoder ends the json with a newline.
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(data.RawValue()); err != nil {
+		return fmt.Errorf("couldn't serialize the Data: %s", err)
+	}
+	raw := buf.Bytes()
+	var err error
+	switch m.format() {
+	case "json":
+	case "yaml":
+		raw, err = yaml.JSONToYAML(raw)
+		if err != nil {
+			return fmt.Errorf("couldn't serialize the Data: %s", err)
+		}
+	default:
+		return m.formatError()
+	}
+
+	if err := os.MkdirAll(filepath.Dir(m.path), 0755); err != nil {
+		return fmt.Errorf("couldn't create the golden file's directory: %s", err)
+	}
+	if err := os.WriteFile(m.path, raw, 0644); err != nil {
+		return fmt.Errorf("couldn't write the golden file: %s", err)
+	}
+	return nil
+}
+
+func (m MatchGoldenFileMatcher) format() string {
+	switch strings.ToLower(filepath.Ext(m.path)) {
+	case ".json":
+		return "json"
+	case ".yml", ".yaml":
+		return "yaml"
+	default:
+		return ""
+	}
+}
+
+func (m MatchGoldenFileMatcher) formatError() error {
+	return fmt.Errorf("can't tell the format of the golden file '%s': it should end in .json, .yml or .yaml", m.path)
+}
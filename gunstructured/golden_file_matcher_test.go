@@ -0,0 +1,93 @@
+package gunstructured_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/totherme/unstructured"
+	"github.com/totherme/unstructured/gunstructured"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MatchGoldenFileMatcher", func() {
+	var (
+		dir  string
+		json unstructured.Data
+	)
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		GinkgoT().Setenv(gunstructured.UpdateGoldenEnv, "")
+
+		var err error
+		json, err = unstructured.ParseJSON(`{"name": "fred", "othernames": ["alice", "bob"]}`)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	writeFile := func(name, contents string) string {
+		path := filepath.Join(dir, name)
+		Expect(os.WriteFile(path, []byte(contents), 0644)).To(Succeed())
+		return path
+	}
+
+	It("compares against json and yaml golden files", func() {
+		Expect(json).To(gunstructured.MatchGoldenFile(writeFile("golden.json", `{"othernames": ["alice", "bob"], "name": "fred"}`)))
+		Expect(json).To(gunstructured.MatchGoldenFile(writeFile("golden.yml", "name: fred\nothernames: [alice, bob]\n")))
+		Expect(json).NotTo(gunstructured.MatchGoldenFile(writeFile("other.yaml", "name: alice\n")))
+	})
+
+	It("accepts DiffOptions", func() {
+		Expect(json).To(gunstructured.MatchGoldenFile(writeFile("golden.yml", "name: alice\nothernames: [bob, alice]\n"),
+			unstructured.IgnoreAt("/name"), unstructured.UnorderedListsAt("/othernames")))
+	})
+
+	It("lists the differences and explains how to update the file", func() {
+		path := writeFile("golden.yml", "name: alice\nothernames: [alice, bob]\n")
+		message := gunstructured.MatchGoldenFile(path).FailureMessage(json)
+		Expect(message).To(ContainSubstring(`'/name': expected "alice", got "fred"`))
+		Expect(message).To(ContainSubstring("run the tests again with UPDATE_GOLDEN=1 to update '" + path + "'"))
+	})
+
+	It("returns errors for missing files and unknown formats", func() {
+		_, err := gunstructured.MatchGoldenFile(filepath.Join(dir, "missing.json")).Match(json)
+		Expect(err).To(MatchError(ContainSubstring("doesn't exist. Run the tests with UPDATE_GOLDEN=1 to create it")))
+
+		_, err = gunstructured.MatchGoldenFile(writeFile("golden.txt", "{}")).Match(json)
+		Expect(err).To(MatchError(ContainSubstring("it should end in .json, .yml or .yaml")))
+	})
+
+	Context("when UPDATE_GOLDEN is set", func() {
+		BeforeEach(func() {
+			GinkgoT().Setenv(gunstructured.UpdateGoldenEnv, "1")
+		})
+
+		It("writes the golden file, and matches", func() {
+			jsonPath := filepath.Join(dir, "new", "golden.json")
+			yamlPath := filepath.Join(dir, "golden.yaml")
+			Expect(json).To(gunstructured.MatchGoldenFile(jsonPath))
+			Expect(json).To(gunstructured.MatchGoldenFile(yamlPath))
+
+			Expect(os.ReadFile(jsonPath)).To(BeEquivalentTo(`{
+  "name": "fred",
+  "othernames": [
+    "alice",
+    "bob"
+  ]
+}
+`))
+			Expect(os.ReadFile(yamlPath)).To(BeEquivalentTo("name: fred\nothernames:\n- alice\n- bob\n"))
+
+			GinkgoT().Setenv(gunstructured.UpdateGoldenEnv, "")
+			Expect(json).To(gunstructured.MatchGoldenFile(jsonPath))
+			Expect(json).To(gunstructured.MatchGoldenFile(yamlPath))
+		})
+
+		It("doesn't escape HTML characters in json", func() {
+			jsonPath := filepath.Join(dir, "html.json")
+			Expect(`{query: "a<b && c>d"}`).To(gunstructured.MatchGoldenFile(jsonPath))
+			Expect(os.ReadFile(jsonPath)).To(BeEquivalentTo("{\n  \"query\": \"a<b && c>d\"\n}\n"))
+		})
+	})
+})
@@ -2,36 +2,37 @@ package gunstructured
 
 import (
 	"fmt"
-
-	"github.com/totherme/unstructured"
 )
 
 // HaveJSONKeyMatcher is a gomega matcher which tests if a given value
 // represents a json object containing a particular key.
 type HaveJSONKeyMatcher struct {
-	key string
+	key   string
+	cache *actualCache
 }
 
 // HaveJSONKey returns a gomega matcher  which tests if a given value
 // represents an unstructured object containing a given `key`.
 func HaveJSONKey(key string) HaveJSONKeyMatcher {
-	return HaveJSONKeyMatcher{key: key}
+	return HaveJSONKeyMatcher{key: key, cache: newActualCache()}
 }
 
 // HaveYAMLKey is exactly like HaveJSONKey
 func HaveYAMLKey(key string) HaveJSONKeyMatcher {
-	return HaveJSONKeyMatcher{key: key}
+	return HaveJSONKeyMatcher{key: key, cache: newActualCache()}
 }
 
 // Match is the gomega function that actually checks if the given value
 // represents a json object containing the particular key.
 func (m HaveJSONKeyMatcher) Match(actual interface{}) (bool, error) {
-	switch j := actual.(type) {
-	default:
-		return false, fmt.Errorf("not a Data object. Have you done unstructured.Parse[JSON|YAML](...)?")
-	case unstructured.Data:
-		return j.HasKey(m.key), nil
+	j, err := m.cache.toData(actual)
+	if err != nil {
+		return false, err
+	}
+	if !j.IsOb() {
+		return false, fmt.Errorf("the Data is %s, not an object", describeKind(j.Type()))
 	}
+	return j.HasKey(m.key), nil
 }
 
 // FailureMessage constructs a hopefully-helpful error message in the case that
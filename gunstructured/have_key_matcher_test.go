@@ -48,13 +48,21 @@ var _ = Describe("HaveJSONKeyMatcher", func() {
 				Entry("an absent key", "badgers"),
 			)
 		})
-		Context("when we give it a non-json object", func() {
+		Context("when we give it something that isn't json", func() {
 			It("returns a helpful error message", func() {
 				matcher := gunstructured.HaveJSONKey("key")
-				_, err := matcher.Match(`{"you":"might almost think this would work"}`)
+				_, err := matcher.Match(struct{ You string }{"might almost think this would work"})
 				Expect(err).To(MatchError(ContainSubstring("not a Data object. Have you done unstructured.Parse[JSON|YAML](...)?")))
 			})
 		})
+		Context("when we give it data which isn't an object", func() {
+			It("returns an error rather than panicking", func() {
+				_, err := gunstructured.HaveJSONKey("key").Match("[1, 2]")
+				Expect(err).To(MatchError("the Data is a list, not an object"))
+				_, err = gunstructured.HaveJSONKey("key").Match(json.F("name"))
+				Expect(err).To(MatchError("the Data is a string, not an object"))
+			})
+		})
 	})
 
 	Describe("FailureMessage", func() {
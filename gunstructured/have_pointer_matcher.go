@@ -2,14 +2,13 @@ package gunstructured
 
 import (
 	"fmt"
-
-	"github.com/totherme/unstructured"
 )
 
 // HaveJSONPointerMatcher is a gomega matcher which tests if a given value
 // represents a json object containing a particular json pointer.
 type HaveJSONPointerMatcher struct {
-	p string
+	p     string
+	cache *actualCache
 }
 
 // HaveJSONPointer returns a gomega matcher  which tests if a given value
@@ -18,19 +17,17 @@ type HaveJSONPointerMatcher struct {
 // For more information on json pointers see
 // https://tools.ietf.org/html/rfc6901
 func HaveJSONPointer(p string) HaveJSONPointerMatcher {
-	return HaveJSONPointerMatcher{p: p}
+	return HaveJSONPointerMatcher{p: p, cache: newActualCache()}
 }
 
 // Match is the gomega function that actually checks if the given value
 // represents a json object containing the particular pointer.
 func (m HaveJSONPointerMatcher) Match(actual interface{}) (bool, error) {
-
-	switch t := actual.(type) {
-	default:
-		return false, fmt.Errorf("not a Data object. Have you done unstructured.Parse[JSON|YAML](...)?")
-	case unstructured.Data:
-		return t.HasPointer(m.p)
+	t, err := m.cache.toData(actual)
+	if err != nil {
+		return false, err
 	}
+	return t.HasPointer(m.p)
 }
 
 // FailureMessage constructs a hopefully-helpful error message in the case that
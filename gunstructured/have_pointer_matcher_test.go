@@ -53,10 +53,10 @@ var _ = Describe("HavePointerMatcher", func() {
 			)
 		})
 
-		Context("when we give it a non-json object", func() {
+		Context("when we give it something that isn't json", func() {
 			It("returns a helpful error message", func() {
 				matcher := gunstructured.HaveJSONPointer("/perfectly/valid")
-				_, err := matcher.Match(`{"you":"might almost think this would work"}`)
+				_, err := matcher.Match(struct{ You string }{"might almost think this would work"})
 				Expect(err).To(MatchError(ContainSubstring("not a Data object. Have you done unstructured.Parse[JSON|YAML](...)?")))
 			})
 		})
@@ -0,0 +1,107 @@
+package gunstructured
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/onsi/gomega/types"
+	"github.com/totherme/unstructured"
+)
+
+// HaveValueAtMatcher is a gomega matcher which tests if a given value
+// represents a json object with something at a particular json pointer, and
+// if that something satisfies another matcher.
+type HaveValueAtMatcher struct {
+	p       string
+	matcher types.GomegaMatcher
+	asData  bool
+	cache   *actualCache
+}
+
+// HaveValueAt returns a gomega matcher which tests if a given value
+// represents a json object with a value at the json pointer `p`, which
+// satisfies `matcher`. The value is passed to `matcher` as a raw go value, as
+// returned by unstructured.Data.RawValue, so you can write:
+//
+//	Expect(data).To(HaveValueAt("/employees/0/name", Equal("Alex")))
+//
+// Since json numbers are always float64s, use BeNumerically to check them.
+//
+// For more information on json pointers see
+// https://tools.ietf.org/html/rfc6901
+func HaveValueAt(p string, matcher types.GomegaMatcher) HaveValueAtMatcher {
+	return HaveValueAtMatcher{p: p, matcher: matcher, cache: newActualCache()}
+}
+
+// HaveDataAt is like HaveValueAt, but passes the value to `matcher` as an
+// unstructured.Data, so that it can be checked by other gunstructured
+// matchers:
+//
+//	Expect(data).To(HaveDataAt("/employees/0", HaveJSONKey("name")))
+func HaveDataAt(p string, matcher types.GomegaMatcher) HaveValueAtMatcher {
+	return HaveValueAtMatcher{p: p, matcher: matcher, asData: true, cache: newActualCache()}
+}
+
+// Match is the gomega function that actually checks if the given value
+// represents a json object with a value at the particular pointer, and if
+// that value satisfies the nested matcher.
+func (m HaveValueAtMatcher) Match(actual interface{}) (bool, error) {
+	val, found, err := m.valueAt(actual)
+	if err != nil || !found {
+		return false, err
+	}
+	return m.matcher.Match(val)
+}
+
+// FailureMessage constructs a hopefully-helpful error message in the case that
+// there is nothing at the particular pointer, or what's there doesn't satisfy
+// the nested matcher.
+func (m HaveValueAtMatcher) FailureMessage(actual interface{}) (message string) {
+	val, found, err := m.valueAt(actual)
+	if err != nil || !found {
+		actualString := fmt.Sprintf("%+v", actual)
+		return fmt.Sprintf("expected '%s' to be an unstructured.Data object with pointer '%s'",
+			truncateString(actualString),
+			m.p)
+	}
+	return fmt.Sprintf("at pointer '%s', found %s\n%s", m.p, describe(val), m.matcher.FailureMessage(val))
+}
+
+// NegatedFailureMessage constructs a hopefully-helpful error message in the
+// case that the value at the particular pointer unexpectedly satisfies the
+// nested matcher.
+func (m HaveValueAtMatcher) NegatedFailureMessage(actual interface{}) (message string) {
+	val, _, _ := m.valueAt(actual)
+	return fmt.Sprintf("at pointer '%s', found %s\n%s", m.p, describe(val), m.matcher.NegatedFailureMessage(val))
+}
+
+func (m HaveValueAtMatcher) valueAt(actual interface{}) (val interface{}, found bool, err error) {
+	data, err := m.cache.toData(actual)
+	if err != nil {
+		return nil, false, err
+	}
+	found, err = data.HasPointer(m.p)
+	if err != nil || !found {
+		return nil, found, err
+	}
+	d, err := data.GetByPointer(m.p)
+	if err != nil {
+		return nil, false, err
+	}
+	if m.asData {
+		return d, true, nil
+	}
+	return d.RawValue(), true, nil
+}
+
+// describe renders a value found in some unstructured data as compact json.
+func describe(val interface{}) string {
+	if d, ok := val.(unstructured.Data); ok {
+		val = d.RawValue()
+	}
+	encoded, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Sprintf("%+v", val)
+	}
+	return string(encoded)
+}
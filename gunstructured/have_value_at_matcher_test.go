@@ -0,0 +1,77 @@
+package gunstructured_test
+
+import (
+	"github.com/totherme/unstructured"
+	"github.com/totherme/unstructured/gunstructured"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HaveValueAtMatcher", func() {
+	var json unstructured.Data
+
+	BeforeEach(func() {
+		var err error
+		json, err = unstructured.ParseJSON(`{"employees": [{"name": "Alex", "age": 42}], "not": null}`)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Describe("HaveValueAt", func() {
+		It("matches the raw value at the pointer against the nested matcher", func() {
+			Expect(json).To(gunstructured.HaveValueAt("/employees/0/name", Equal("Alex")))
+			Expect(json).To(gunstructured.HaveValueAt("/employees/name=Alex/age", BeNumerically("==", 42)))
+			Expect(json).To(gunstructured.HaveValueAt("/not", BeNil()))
+			Expect(json).NotTo(gunstructured.HaveValueAt("/employees/0/name", Equal("Sam")))
+		})
+
+		It("doesn't match when there's nothing at the pointer", func() {
+			Expect(json).NotTo(gunstructured.HaveValueAt("/employees/1/name", Equal("Alex")))
+		})
+
+		It("returns an error for an invalid pointer", func() {
+			_, err := gunstructured.HaveValueAt("employees", BeNil()).Match(json)
+			Expect(err).To(MatchError(ContainSubstring("JSON pointer must be empty or start with a \"/\"")))
+		})
+
+		It("returns an error when given something it can't turn into Data", func() {
+			_, err := gunstructured.HaveValueAt("/name", BeNil()).Match(42)
+			Expect(err).To(MatchError(ContainSubstring("not a Data object. Have you done unstructured.Parse[JSON|YAML](...)?")))
+		})
+	})
+
+	Describe("HaveDataAt", func() {
+		It("matches the Data at the pointer against the nested matcher", func() {
+			Expect(json).To(gunstructured.HaveDataAt("/employees/0/name", gunstructured.BeAString()))
+			Expect(json).To(gunstructured.HaveDataAt("/employees/0", gunstructured.HaveJSONKey("age")))
+			Expect(json).NotTo(gunstructured.HaveDataAt("/employees", gunstructured.BeAnObject()))
+		})
+	})
+
+	Describe("FailureMessage", func() {
+		It("shows the pointer, the value found and the nested failure message", func() {
+			message := gunstructured.HaveValueAt("/employees/0/name", Equal("Sam")).FailureMessage(json)
+			Expect(message).To(HavePrefix("at pointer '/employees/0/name', found \"Alex\"\n"))
+			Expect(message).To(ContainSubstring(Equal("Sam").FailureMessage("Alex")))
+		})
+
+		It("renders Data values as json", func() {
+			message := gunstructured.HaveDataAt("/employees/0", gunstructured.BeAList()).FailureMessage(json)
+			Expect(message).To(HavePrefix(`at pointer '/employees/0', found {"age":42,"name":"Alex"}`))
+			Expect(message).To(ContainSubstring("expected a Data list -- got a Data object"))
+		})
+
+		It("says when there's nothing at the pointer", func() {
+			Expect(gunstructured.HaveValueAt("/missing", BeNil()).FailureMessage(json)).
+				To(ContainSubstring("to be an unstructured.Data object with pointer '/missing'"))
+		})
+	})
+
+	Describe("NegatedFailureMessage", func() {
+		It("shows the pointer, the value found and the nested negated failure message", func() {
+			message := gunstructured.HaveValueAt("/employees/0/name", Equal("Alex")).NegatedFailureMessage(json)
+			Expect(message).To(HavePrefix("at pointer '/employees/0/name', found \"Alex\"\n"))
+			Expect(message).To(ContainSubstring(Equal("Alex").NegatedFailureMessage("Alex")))
+		})
+	})
+})
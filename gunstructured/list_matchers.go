@@ -0,0 +1,369 @@
+package gunstructured
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/onsi/gomega/types"
+	"github.com/totherme/unstructured"
+)
+
+// The list matchers pass each element of a list to their nested matchers as
+// an unstructured.Data, so that other gunstructured matchers can check it.
+// To compare an element, or part of one, with a plain go value, nest
+// HaveValueAt. The empty pointer refers to the whole element:
+//
+//	Expect(data).To(HaveDataAt("/employees",
+//		ContainElementMatching(HaveValueAt("/name", Equal("Alex")))))
+//	Expect(data).To(HaveDataAt("/tags", AllElements(HaveValueAt("", HavePrefix("v")))))
+
+// HaveDataLenMatcher is a gomega matcher which tests if a given value
+// represents a json list or object of a particular length.
+type HaveDataLenMatcher struct {
+	n     int
+	cache *actualCache
+}
+
+// HaveDataLen returns a gomega matcher which tests if a given value
+// represents a json list with `n` elements, or a json object with `n` keys.
+func HaveDataLen(n int) HaveDataLenMatcher {
+	return HaveDataLenMatcher{n: n, cache: newActualCache()}
+}
+
+// Match is the gomega function that actually checks the length of the given
+// value.
+func (m HaveDataLenMatcher) Match(actual interface{}) (bool, error) {
+	length, err := m.cache.dataLen(actual)
+	if err != nil {
+		return false, err
+	}
+	return length == m.n, nil
+}
+
+// FailureMessage constructs a hopefully-helpful error message in the case that
+// the given value has the wrong length.
+func (m HaveDataLenMatcher) FailureMessage(actual interface{}) (message string) {
+	length, err := m.cache.dataLen(actual)
+	if err != nil {
+		return err.Error()
+	}
+	return fmt.Sprintf("expected %s to have length %d, but it has length %d",
+		truncateString(m.cache.describeActual(actual)), m.n, length)
+}
+
+// NegatedFailureMessage constructs a hopefully-helpful error message in the
+// case that the given value unexpectedly has the particular length.
+func (m HaveDataLenMatcher) NegatedFailureMessage(actual interface{}) (message string) {
+	return fmt.Sprintf("expected %s not to have length %d", truncateString(m.cache.describeActual(actual)), m.n)
+}
+
+func (c *actualCache) dataLen(actual interface{}) (int, error) {
+	data, err := c.toData(actual)
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case data.IsList():
+		return len(data.UnsafeListValue()), nil
+	case data.IsOb():
+		return len(data.UnsafeObValue()), nil
+	default:
+		return 0, fmt.Errorf("the Data is %s, so it has no length", describeKind(data.Type()))
+	}
+}
+
+// ContainElementMatchingMatcher is a gomega matcher which tests if a given
+// value represents a json list with at least one element satisfying another
+// matcher.
+type ContainElementMatchingMatcher struct {
+	matcher types.GomegaMatcher
+	cache   *actualCache
+}
+
+// ContainElementMatching returns a gomega matcher which tests if a given
+// value represents a json list with at least one element which satisfies
+// `matcher`.
+func ContainElementMatching(matcher types.GomegaMatcher) ContainElementMatchingMatcher {
+	return ContainElementMatchingMatcher{matcher: matcher, cache: newActualCache()}
+}
+
+// Match is the gomega function that actually checks the elements of the
+// given value.
+func (m ContainElementMatchingMatcher) Match(actual interface{}) (bool, error) {
+	elems, err := m.cache.listElems(actual)
+	if err != nil {
+		return false, err
+	}
+	for _, elem := range elems {
+		if ok, err := m.matcher.Match(elem); err == nil && ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// FailureMessage constructs a hopefully-helpful error message in the case that
+// no element of the given value satisfies the nested matcher.
+func (m ContainElementMatchingMatcher) FailureMessage(actual interface{}) (message string) {
+	elems, err := m.cache.listElems(actual)
+	if err != nil {
+		return err.Error()
+	}
+	if len(elems) == 0 {
+		return "expected an element to match, but the list is empty"
+	}
+	return fmt.Sprintf("expected an element of %s to match, but none of its %d elements did. The first failed with:\n%s",
+		truncateString(m.cache.describeActual(actual)), len(elems), m.matcher.FailureMessage(elems[0]))
+}
+
+// NegatedFailureMessage constructs a hopefully-helpful error message in the
+// case that an element of the given value unexpectedly satisfies the nested
+// matcher.
+func (m ContainElementMatchingMatcher) NegatedFailureMessage(actual interface{}) (message string) {
+	elems, _ := m.cache.listElems(actual)
+	for i, elem := range elems {
+		if ok, err := m.matcher.Match(elem); err == nil && ok {
+			return fmt.Sprintf("expected no element to match, but at pointer '/%d', found %s\n%s",
+				i, describe(elem), m.matcher.NegatedFailureMessage(elem))
+		}
+	}
+	return "expected no element to match"
+}
+
+// AllElementsMatcher is a gomega matcher which tests if a given value
+// represents a json list whose elements all satisfy another matcher.
+type AllElementsMatcher struct {
+	matcher types.GomegaMatcher
+	cache   *actualCache
+}
+
+// AllElements returns a gomega matcher which tests if a given value represents
+// a json list whose elements all satisfy `matcher`. An empty list always
+// matches.
+func AllElements(matcher types.GomegaMatcher) AllElementsMatcher {
+	return AllElementsMatcher{matcher: matcher, cache: newActualCache()}
+}
+
+// Match is the gomega function that actually checks the elements of the
+// given value.
+func (m AllElementsMatcher) Match(actual interface{}) (bool, error) {
+	_, _, found, err := m.firstFailure(actual)
+	return err == nil && !found, err
+}
+
+// FailureMessage constructs a hopefully-helpful error message in the case that
+// some element of the given value doesn't satisfy the nested matcher, showing
+// the first such element.
+func (m AllElementsMatcher) FailureMessage(actual interface{}) (message string) {
+	index, elem, _, err := m.firstFailure(actual)
+	if err != nil {
+		return err.Error()
+	}
+	return fmt.Sprintf("at pointer '/%d', found %s\n%s", index, describe(elem), m.matcher.FailureMessage(elem))
+}
+
+// NegatedFailureMessage constructs a hopefully-helpful error message in the
+// case that every element of the given value unexpectedly satisfies the
+// nested matcher.
+func (m AllElementsMatcher) NegatedFailureMessage(actual interface{}) (message string) {
+	return fmt.Sprintf("expected some element of %s not to match, but they all did",
+		truncateString(m.cache.describeActual(actual)))
+}
+
+func (m AllElementsMatcher) firstFailure(actual interface{}) (int, unstructured.Data, bool, error) {
+	elems, err := m.cache.listElems(actual)
+	if err != nil {
+		return 0, unstructured.Data{}, false, err
+	}
+	for i, elem := range elems {
+		ok, err := m.matcher.Match(elem)
+		if err != nil {
+			return 0, unstructured.Data{}, false, fmt.Errorf("at pointer '/%d': %s", i, err)
+		}
+		if !ok {
+			return i, elem, true, nil
+		}
+	}
+	return 0, unstructured.Data{}, false, nil
+}
+
+// ConsistOfDataMatcher is a gomega matcher which tests if a given value
+// represents a json list with exactly some expected elements, in any order.
+type ConsistOfDataMatcher struct {
+	elements []interface{}
+	cache    *actualCache
+}
+
+// ConsistOfData returns a gomega matcher which tests if a given value
+// represents a json list whose elements can be paired up one-to-one with
+// `elements`, in any order. Each of `elements` is either a gomega matcher,
+// which the paired element must satisfy, or a value which the paired element
+// must be structurally equal to. Such values are either unstructured.Data, or
+// go values, which are compared as if they had been marshalled to json. So
+// strings are compared as strings, rather than parsed as yaml:
+//
+//	Expect(data).To(HaveDataAt("/tags", ConsistOfData("b", "yes")))
+//	Expect(data).To(HaveDataAt("/employees", ConsistOfData(
+//		map[string]interface{}{"name": "Alex"},
+//		HaveValueAt("/name", Equal("Sam")))))
+func ConsistOfData(elements ...interface{}) ConsistOfDataMatcher {
+	return ConsistOfDataMatcher{elements: elements, cache: newActualCache()}
+}
+
+// Match is the gomega function that actually checks the elements of the given
+// value.
+func (m ConsistOfDataMatcher) Match(actual interface{}) (bool, error) {
+	unmatchedElems, unmatchedExpected, err := m.pair(actual)
+	if err != nil {
+		return false, err
+	}
+	return len(unmatchedElems) == 0 && len(unmatchedExpected) == 0, nil
+}
+
+// FailureMessage constructs a hopefully-helpful error message in the case that
+// the elements of the given value can't be paired up with the expected ones,
+// listing those which are left over.
+func (m ConsistOfDataMatcher) FailureMessage(actual interface{}) (message string) {
+	unmatchedElems, unmatchedExpected, err := m.pair(actual)
+	if err != nil {
+		return err.Error()
+	}
+	elems, _ := m.cache.listElems(actual)
+	var lines []string
+	for _, i := range unmatchedElems {
+		lines = append(lines, fmt.Sprintf("  unexpected element at pointer '/%d': %s", i, describe(elems[i])))
+	}
+	for _, i := range unmatchedExpected {
+		lines = append(lines, fmt.Sprintf("  missing element: %s", describeExpectation(m.elements[i])))
+	}
+	return fmt.Sprintf("expected %s to consist of %d elements, but:\n%s",
+		truncateString(m.cache.describeActual(actual)), len(m.elements), strings.Join(lines, "\n"))
+}
+
+// NegatedFailureMessage constructs a hopefully-helpful error message in the
+// case that the elements of the given value unexpectedly pair up with the
+// expected ones.
+func (m ConsistOfDataMatcher) NegatedFailureMessage(actual interface{}) (message string) {
+	return fmt.Sprintf("expected %s not to consist of the %d given elements",
+		truncateString(m.cache.describeActual(actual)), len(m.elements))
+}
+
+// pair pairs up elements with expectations as far as possible, and returns
+// the indexes of the elements and expectations which are left over.
+func (m ConsistOfDataMatcher) pair(actual interface{}) (unmatchedElems, unmatchedExpected []int, err error) {
+	elems, err := m.cache.listElems(actual)
+	if err != nil {
+		return nil, nil, err
+	}
+	matches := make([][]bool, len(m.elements))
+	for i, element := range m.elements {
+		matches[i] = make([]bool, len(elems))
+		for j, elem := range elems {
+			matches[i][j], err = satisfies(elem, element)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	// Find a maximum pairing by repeatedly looking for augmenting paths.
+	pairedWith := make([]int, len(elems))
+	for j := range pairedWith {
+		pairedWith[j] = -1
+	}
+	var augment func(i int, seen []bool) bool
+	augment = func(i int, seen []bool) bool {
+		for j := range elems {
+			if !matches[i][j] || seen[j] {
+				continue
+			}
+			seen[j] = true
+			if pairedWith[j] == -1 || augment(pairedWith[j], seen) {
+				pairedWith[j] = i
+				return true
+			}
+		}
+		return false
+	}
+	for i := range m.elements {
+		if !augment(i, make([]bool, len(elems))) {
+			unmatchedExpected = append(unmatchedExpected, i)
+		}
+	}
+	for j, i := range pairedWith {
+		if i == -1 {
+			unmatchedElems = append(unmatchedElems, j)
+		}
+	}
+	return unmatchedElems, unmatchedExpected, nil
+}
+
+func satisfies(elem unstructured.Data, expected interface{}) (bool, error) {
+	if matcher, ok := expected.(types.GomegaMatcher); ok {
+		ok, err := matcher.Match(elem)
+		return err == nil && ok, nil
+	}
+	want, err := expectedElement(expected)
+	if err != nil {
+		return false, fmt.Errorf("couldn't use the expected element: %s", err)
+	}
+	diffs, err := unstructured.Diff(want, elem)
+	return err == nil && len(diffs) == 0, err
+}
+
+// expectedElement turns a value given to ConsistOfData into Data. Unlike
+// toData, it doesn't parse strings as documents, so "yes" is the string
+// "yes" and not true.
+func expectedElement(expected interface{}) (unstructured.Data, error) {
+	if d, ok := expected.(unstructured.Data); ok {
+		return d, nil
+	}
+	encoded, err := json.Marshal(expected)
+	if err != nil {
+		return unstructured.Data{}, fmt.Errorf("a %T can't be converted to json: %s", expected, err)
+	}
+	return unstructured.ParseJSON(string(encoded))
+}
+
+func describeExpectation(expected interface{}) string {
+	if matcher, ok := expected.(types.GomegaMatcher); ok {
+		return fmt.Sprintf("an element matching %T", matcher)
+	}
+	if want, err := expectedElement(expected); err == nil {
+		return describe(want)
+	}
+	return fmt.Sprintf("%+v", expected)
+}
+
+func (c *actualCache) listElems(actual interface{}) ([]unstructured.Data, error) {
+	data, err := c.toData(actual)
+	if err != nil {
+		return nil, err
+	}
+	if !data.IsList() {
+		return nil, fmt.Errorf("the Data is %s, not a list", describeKind(data.Type()))
+	}
+	return data.UnsafeListValue(), nil
+}
+
+// describeActual renders the actual value given to a matcher as compact json,
+// if it can be turned into Data.
+func (c *actualCache) describeActual(actual interface{}) string {
+	data, err := c.toData(actual)
+	if err != nil {
+		return fmt.Sprintf("%+v", actual)
+	}
+	return describe(data)
+}
+
+func describeKind(kind unstructured.Kind) string {
+	switch kind {
+	case unstructured.KindOb:
+		return "an object"
+	case unstructured.KindNull:
+		return "null"
+	default:
+		return "a " + string(kind)
+	}
+}
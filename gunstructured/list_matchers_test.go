@@ -0,0 +1,124 @@
+package gunstructured_test
+
+import (
+	"github.com/totherme/unstructured"
+	"github.com/totherme/unstructured/gunstructured"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("The list matchers", func() {
+	var json unstructured.Data
+
+	BeforeEach(func() {
+		var err error
+		json, err = unstructured.ParseJSON(`{
+			"employees": [
+				{"name": "Alex", "band": 12},
+				{"name": "Sue", "band": 8},
+				{"name": "Hilary", "band": 14}
+			],
+			"tags": ["v1", "v2"],
+			"name": "hr-data"
+		}`)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Describe("HaveDataLen", func() {
+		It("checks the length of lists and objects", func() {
+			Expect(json).To(gunstructured.HaveDataAt("/employees", gunstructured.HaveDataLen(3)))
+			Expect(json).To(gunstructured.HaveValueAt("/tags", gunstructured.HaveDataLen(2)))
+			Expect(json).To(gunstructured.HaveDataLen(3))
+			Expect(json).NotTo(gunstructured.HaveDataAt("/employees", gunstructured.HaveDataLen(2)))
+		})
+
+		It("returns an error for things with no length", func() {
+			_, err := gunstructured.HaveDataLen(1).Match(json.F("name"))
+			Expect(err).To(MatchError("the Data is a string, so it has no length"))
+		})
+
+		It("reports the actual length", func() {
+			Expect(gunstructured.HaveDataLen(3).FailureMessage(json.F("tags"))).
+				To(Equal(`expected ["v1","v2"] to have length 3, but it has length 2`))
+		})
+	})
+
+	Describe("ContainElementMatching", func() {
+		It("matches lists with a matching element", func() {
+			Expect(json).To(gunstructured.HaveDataAt("/employees",
+				gunstructured.ContainElementMatching(gunstructured.HaveValueAt("/name", Equal("Sue")))))
+			Expect(json).NotTo(gunstructured.HaveDataAt("/employees",
+				gunstructured.ContainElementMatching(gunstructured.HaveValueAt("/name", Equal("Bob")))))
+		})
+
+		It("returns an error for things which aren't lists", func() {
+			_, err := gunstructured.ContainElementMatching(gunstructured.BeAString()).Match(json)
+			Expect(err).To(MatchError("the Data is an object, not a list"))
+		})
+
+		It("shows which element matched when negated", func() {
+			message := gunstructured.ContainElementMatching(gunstructured.HaveValueAt("", Equal("v2"))).NegatedFailureMessage(json.F("tags"))
+			Expect(message).To(HavePrefix(`expected no element to match, but at pointer '/1', found "v2"`))
+		})
+	})
+
+	Describe("AllElements", func() {
+		It("matches lists whose elements all match", func() {
+			Expect(json).To(gunstructured.HaveDataAt("/employees", gunstructured.AllElements(gunstructured.HaveJSONKey("band"))))
+			Expect(json).To(gunstructured.HaveDataAt("/tags", gunstructured.AllElements(gunstructured.HaveValueAt("", HavePrefix("v")))))
+			Expect(json).NotTo(gunstructured.HaveDataAt("/employees",
+				gunstructured.AllElements(gunstructured.HaveValueAt("/band", BeNumerically(">", 10)))))
+		})
+
+		It("matches empty lists", func() {
+			Expect("[]").To(gunstructured.AllElements(gunstructured.BeAString()))
+		})
+
+		It("reports the first failing element", func() {
+			message := gunstructured.AllElements(gunstructured.HaveValueAt("/band", BeNumerically(">", 10))).FailureMessage(json.F("employees"))
+			Expect(message).To(HavePrefix(`at pointer '/1', found {"band":8,"name":"Sue"}` + "\nat pointer '/band', found 8\n"))
+		})
+	})
+
+	Describe("ConsistOfData", func() {
+		It("pairs up elements with values or matchers in any order", func() {
+			Expect(json).To(gunstructured.HaveDataAt("/tags", gunstructured.ConsistOfData("v2", "v1")))
+			Expect(json).To(gunstructured.HaveDataAt("/employees", gunstructured.ConsistOfData(
+				gunstructured.HaveValueAt("/name", Equal("Hilary")),
+				map[string]interface{}{"name": "Alex", "band": 12},
+				gunstructured.HaveJSONKey("name"),
+			)))
+		})
+
+		It("finds a pairing even when a greedy one would fail", func() {
+			Expect(json).To(gunstructured.HaveDataAt("/tags", gunstructured.ConsistOfData(
+				gunstructured.HaveValueAt("", HavePrefix("v")),
+				"v1",
+			)))
+		})
+
+		It("compares strings as strings, rather than parsing them", func() {
+			list, err := unstructured.ParseJSON(`["yes", "1.0", "null"]`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(list).To(gunstructured.ConsistOfData("null", "yes", "1.0"))
+			Expect(list).NotTo(gunstructured.ConsistOfData(true, 1, nil))
+		})
+
+		It("returns an error for values which can't be converted to json", func() {
+			_, err := gunstructured.ConsistOfData(func() {}).Match(json.F("tags"))
+			Expect(err).To(MatchError(ContainSubstring("couldn't use the expected element: a func() can't be converted to json")))
+		})
+
+		It("doesn't match when there are missing or extra elements", func() {
+			Expect(json).NotTo(gunstructured.HaveDataAt("/tags", gunstructured.ConsistOfData("v1")))
+			Expect(json).NotTo(gunstructured.HaveDataAt("/tags", gunstructured.ConsistOfData("v1", "v2", "v3")))
+		})
+
+		It("lists the elements left over", func() {
+			Expect(gunstructured.ConsistOfData("v1", "v3").FailureMessage(json.F("tags"))).To(Equal(`expected ["v1","v2"] to consist of 2 elements, but:
+  unexpected element at pointer '/1': "v2"
+  missing element: "v3"`))
+		})
+	})
+})
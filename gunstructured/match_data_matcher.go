@@ -0,0 +1,88 @@
+package gunstructured
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/totherme/unstructured"
+)
+
+// MatchDataMatcher is a gomega matcher which tests if a given value
+// represents the same unstructured data as some expected document.
+type MatchDataMatcher struct {
+	expected unstructured.Data
+	parseErr error
+	opts     []unstructured.DiffOption
+	cache    *actualCache
+}
+
+// MatchData returns a gomega matcher which tests if a given value represents
+// the same data as `expected`. When the match fails, the failure message
+// lists every pointer at which the documents differ, as found by
+// unstructured.Diff. DiffOptions such as unstructured.IgnoreAt and
+// unstructured.UnorderedListsAt can relax the comparison:
+//
+//	Expect(response).To(MatchData(expected,
+//		unstructured.IgnoreAt("/metadata/timestamp"),
+//		unstructured.UnorderedListsAt("/employees")))
+func MatchData(expected unstructured.Data, opts ...unstructured.DiffOption) MatchDataMatcher {
+	return MatchDataMatcher{expected: expected, opts: opts, cache: newActualCache()}
+}
+
+// MatchJSONData is like MatchData, but parses the expected document from a
+// json string. It isn't called MatchJSON, so that it doesn't clash with
+// gomega's own matcher when both packages are dot-imported.
+func MatchJSONData(expected string, opts ...unstructured.DiffOption) MatchDataMatcher {
+	data, err := unstructured.ParseJSON(expected)
+	return MatchDataMatcher{expected: data, parseErr: err, opts: opts, cache: newActualCache()}
+}
+
+// MatchYAMLData is like MatchData, but parses the expected document from a
+// yaml string.
+func MatchYAMLData(expected string, opts ...unstructured.DiffOption) MatchDataMatcher {
+	data, err := unstructured.ParseYAML(expected)
+	return MatchDataMatcher{expected: data, parseErr: err, opts: opts, cache: newActualCache()}
+}
+
+// Match is the gomega function that actually checks if the given value
+// represents the expected data.
+func (m MatchDataMatcher) Match(actual interface{}) (bool, error) {
+	diffs, err := m.diff(actual)
+	if err != nil {
+		return false, err
+	}
+	return len(diffs) == 0, nil
+}
+
+// FailureMessage constructs a hopefully-helpful error message in the case that
+// the given value doesn't represent the expected data, listing each
+// difference.
+func (m MatchDataMatcher) FailureMessage(actual interface{}) (message string) {
+	diffs, err := m.diff(actual)
+	if err != nil {
+		return err.Error()
+	}
+	lines := make([]string, len(diffs))
+	for i, diff := range diffs {
+		lines[i] = "  " + diff.String()
+	}
+	return fmt.Sprintf("expected the Data to match, but found %d difference(s):\n%s",
+		len(diffs), strings.Join(lines, "\n"))
+}
+
+// NegatedFailureMessage constructs a hopefully-helpful error message in the
+// case that the given value unexpectedly represents the expected data.
+func (m MatchDataMatcher) NegatedFailureMessage(actual interface{}) (message string) {
+	return fmt.Sprintf("expected the Data not to match %s", truncateString(describe(m.expected)))
+}
+
+func (m MatchDataMatcher) diff(actual interface{}) ([]unstructured.Difference, error) {
+	if m.parseErr != nil {
+		return nil, fmt.Errorf("couldn't parse the expected data: %s", m.parseErr)
+	}
+	data, err := m.cache.toData(actual)
+	if err != nil {
+		return nil, err
+	}
+	return unstructured.Diff(m.expected, data, m.opts...)
+}
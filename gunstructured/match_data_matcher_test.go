@@ -0,0 +1,72 @@
+package gunstructured_test
+
+import (
+	"github.com/totherme/unstructured"
+	"github.com/totherme/unstructured/gunstructured"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MatchDataMatcher", func() {
+	var json unstructured.Data
+
+	BeforeEach(func() {
+		var err error
+		json, err = unstructured.ParseJSON(`{"name": "fred", "othernames": ["alice", "bob"], "life": 42, "not": null}`)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Describe("Match", func() {
+		It("matches structurally equal data", func() {
+			expected, err := unstructured.ParseYAML("{life: 42, name: fred, not: null, othernames: [alice, bob]}")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(json).To(gunstructured.MatchData(expected))
+			Expect(json).To(gunstructured.MatchYAMLData("{life: 42, name: fred, not: null, othernames: [alice, bob]}"))
+			Expect(json).To(gunstructured.MatchJSONData(`{"life": 42, "name": "fred", "not": null, "othernames": ["alice", "bob"]}`))
+		})
+
+		It("doesn't match different data", func() {
+			Expect(json).NotTo(gunstructured.MatchYAMLData("{life: 43, name: fred, not: null, othernames: [alice, bob]}"))
+			Expect(json).NotTo(gunstructured.MatchYAMLData("{name: fred}"))
+		})
+
+		It("accepts DiffOptions", func() {
+			Expect(json).To(gunstructured.MatchYAMLData("{life: 0, name: fred, not: null, othernames: [bob, alice]}",
+				unstructured.IgnoreAt("/life"), unstructured.UnorderedListsAt("/othernames")))
+		})
+
+		It("returns an error when the expected data doesn't parse", func() {
+			_, err := gunstructured.MatchJSONData(`{"name":`).Match(json)
+			Expect(err).To(MatchError(ContainSubstring("couldn't parse the expected data")))
+		})
+
+		It("returns an error when given something it can't turn into Data", func() {
+			_, err := gunstructured.MatchYAMLData("{}").Match(42)
+			Expect(err).To(MatchError(ContainSubstring("not a Data object. Have you done unstructured.Parse[JSON|YAML](...)?")))
+		})
+	})
+
+	Describe("FailureMessage", func() {
+		It("lists every differing pointer with the expected and actual values", func() {
+			message := gunstructured.MatchYAMLData(`
+name: freddie
+othernames: [alice]
+life: 42
+not: null
+hobbies: {first: golf}
+`).FailureMessage(json)
+			Expect(message).To(Equal(`expected the Data to match, but found 3 difference(s):
+  '/hobbies': expected {"first":"golf"}, but it was missing
+  '/name': expected "freddie", got "fred"
+  '/othernames/1': unexpected "bob"`))
+		})
+	})
+
+	Describe("NegatedFailureMessage", func() {
+		It("shows the expected data", func() {
+			Expect(gunstructured.MatchYAMLData("{name: fred}").NegatedFailureMessage(json)).
+				To(Equal(`expected the Data not to match {"name":"fred"}`))
+		})
+	})
+})
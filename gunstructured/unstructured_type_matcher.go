@@ -7,7 +7,6 @@ package gunstructured
 
 import (
 	"fmt"
-	"reflect"
 
 	"github.com/totherme/unstructured"
 )
@@ -15,14 +14,16 @@ import (
 // DataTypeMatcher is a gomega matcher which tests if a given value represents
 // json data of a given type.
 type DataTypeMatcher struct {
-	typ string
+	typ   unstructured.Kind
+	cache *actualCache
 }
 
 // BeAnObject returns a gomega matcher which tests if a given value represents
 // a json object.
 func BeAnObject() DataTypeMatcher {
 	return DataTypeMatcher{
-		typ: unstructured.DataOb,
+		typ:   unstructured.KindOb,
+		cache: newActualCache(),
 	}
 }
 
@@ -30,7 +31,8 @@ func BeAnObject() DataTypeMatcher {
 // a json string.
 func BeAString() DataTypeMatcher {
 	return DataTypeMatcher{
-		typ: unstructured.DataString,
+		typ:   unstructured.KindString,
+		cache: newActualCache(),
 	}
 }
 
@@ -38,7 +40,8 @@ func BeAString() DataTypeMatcher {
 // a json list.
 func BeAList() DataTypeMatcher {
 	return DataTypeMatcher{
-		typ: unstructured.DataList,
+		typ:   unstructured.KindList,
+		cache: newActualCache(),
 	}
 }
 
@@ -46,7 +49,8 @@ func BeAList() DataTypeMatcher {
 // a json num.
 func BeANum() DataTypeMatcher {
 	return DataTypeMatcher{
-		typ: unstructured.DataNum,
+		typ:   unstructured.KindNum,
+		cache: newActualCache(),
 	}
 }
 
@@ -54,7 +58,8 @@ func BeANum() DataTypeMatcher {
 // a json bool.
 func BeABool() DataTypeMatcher {
 	return DataTypeMatcher{
-		typ: unstructured.DataBool,
+		typ:   unstructured.KindBool,
+		cache: newActualCache(),
 	}
 }
 
@@ -62,41 +67,32 @@ func BeABool() DataTypeMatcher {
 // json null.
 func BeANull() DataTypeMatcher {
 	return DataTypeMatcher{
-		typ: unstructured.DataNull,
+		typ:   unstructured.KindNull,
+		cache: newActualCache(),
 	}
 }
 
 // Match is the gomega function that actually checks if the given value is of
 // the appropriate json type.
 func (m DataTypeMatcher) Match(actual interface{}) (success bool, err error) {
-	switch json := actual.(type) {
-	default:
-		return false, fmt.Errorf("actual is not a Data -- actually of type %s", reflect.TypeOf(actual))
-	case unstructured.Data:
-		return json.IsOfType(m.typ), nil
+	json, err := m.cache.toData(actual)
+	if err != nil {
+		return false, err
 	}
+	return json.Type() == m.typ, nil
 }
 
 // FailureMessage constructs a hopefully-helpful error message in the case that
 // the given value is not of the appropriate json type.
 func (m DataTypeMatcher) FailureMessage(actual interface{}) (message string) {
-	if reflect.TypeOf(actual) != reflect.TypeOf(unstructured.Data{}) {
-		return fmt.Sprintf("expected a Data object -- got a %s", reflect.TypeOf(actual))
+	json, err := m.cache.toData(actual)
+	if err != nil {
+		return fmt.Sprintf("expected a Data %s -- %s", m.typ, err)
 	}
-
-	json := actual.(unstructured.Data)
-	for _, t := range []string{unstructured.DataBool,
-		unstructured.DataString,
-		unstructured.DataNum,
-		unstructured.DataList,
-		unstructured.DataNull,
-		unstructured.DataOb} {
-		if json.IsOfType(t) {
-			return fmt.Sprintf("expected a Data %s -- got a Data %s", m.typ, t)
-		}
+	if json.Type() == "" {
+		return fmt.Sprintf("expected a Data %s -- got some other crazy kind of Data", m.typ)
 	}
-
-	return fmt.Sprintf("expected a Data %s -- got some other crazy kind of Data", m.typ)
+	return fmt.Sprintf("expected a Data %s -- got a Data %s", m.typ, json.Type())
 }
 
 // NegatedFailureMessage constructs a hopefully-helpful error message in the
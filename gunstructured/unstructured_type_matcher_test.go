@@ -11,7 +11,7 @@ import (
 
 type testData struct {
 	Matcher types.GomegaMatcher
-	Typ     string
+	Typ     unstructured.Kind
 }
 
 var _ = Describe("The Data type matchers", func() {
@@ -68,7 +68,9 @@ var _ = Describe("The Data type matchers", func() {
 
 			field := testjson.F(key)
 			for _, td := range matcherSet {
-				Expect(td.Matcher.Match(field)).To(Equal(field.IsOfType(td.Typ)))
+				isOfType, err := field.IsOfType(td.Typ)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(td.Matcher.Match(field)).To(Equal(isOfType))
 			}
 		},
 
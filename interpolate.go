@@ -0,0 +1,164 @@
+package unstructured
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var placeholderRegexp = regexp.MustCompile(`\(\(\s*([-/.~\w\pL]+)\s*\)\)`)
+
+// MissingVariablesError is returned by Interpolate when some placeholders
+// refer to variables which don't exist. It lists every missing variable, not
+// just the first one.
+type MissingVariablesError struct {
+	Names []string
+}
+
+func (e MissingVariablesError) Error() string {
+	return fmt.Sprintf("Expected to find variables: %s", strings.Join(e.Names, ", "))
+}
+
+// An InterpolateOption changes the behaviour of Interpolate.
+type InterpolateOption func(*interpolateConfig)
+
+type interpolateConfig struct {
+	allowMissing bool
+}
+
+// AllowMissingVariables makes Interpolate leave placeholders for missing
+// variables as they are, rather than returning a MissingVariablesError.
+func AllowMissingVariables() InterpolateOption {
+	return func(c *interpolateConfig) {
+		c.allowMissing = true
+	}
+}
+
+// Interpolate returns a copy of `doc` in which every `((name))` placeholder
+// in a string has been replaced with the value of the variable `name` from
+// `vars`. `doc` itself is left unchanged.
+//
+// A string which consists of nothing but a placeholder is replaced by the
+// variable's value, whatever type that is. Placeholders inside longer strings
+// are replaced by the text of the variable, which must be a string, number,
+// bool or null.
+//
+// Variable names may reach inside the variables: `((name.subkey))` refers to
+// the `subkey` field of the variable `name`. For keys containing dots, or to
+// index into lists, a name may instead be a json pointer into `vars`, such as
+// `((/certs/0/private.key))`.
+//
+// If any variables are missing, Interpolate returns a MissingVariablesError
+// naming all of them, unless the AllowMissingVariables option is given.
+func Interpolate(doc Data, vars Data, opts ...InterpolateOption) (Data, error) {
+	config := &interpolateConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+	in := &interpolator{vars: vars, config: config, seen: map[string]bool{}}
+	result, err := in.interpolate(deepCopy(doc.data), nil)
+	if err != nil {
+		return Data{}, err
+	}
+	if len(in.missing) > 0 && !config.allowMissing {
+		return Data{}, MissingVariablesError{Names: in.missing}
+	}
+	return Data{data: result}, nil
+}
+
+type interpolator struct {
+	vars    Data
+	config  *interpolateConfig
+	missing []string
+	seen    map[string]bool
+}
+
+func (in *interpolator) interpolate(node interface{}, path []string) (interface{}, error) {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedKeys(n) {
+			val, err := in.interpolate(n[key], appendToken(path, key))
+			if err != nil {
+				return nil, err
+			}
+			n[key] = val
+		}
+		return n, nil
+	case []interface{}:
+		for i, child := range n {
+			val, err := in.interpolate(child, appendToken(path, strconv.Itoa(i)))
+			if err != nil {
+				return nil, err
+			}
+			n[i] = val
+		}
+		return n, nil
+	case string:
+		return in.interpolateString(n, path)
+	default:
+		return n, nil
+	}
+}
+
+func (in *interpolator) interpolateString(s string, path []string) (interface{}, error) {
+	if match := placeholderRegexp.FindStringSubmatchIndex(s); match != nil && match[0] == 0 && match[1] == len(s) {
+		val, ok := in.lookup(s[match[2]:match[3]])
+		if !ok {
+			return s, nil
+		}
+		return deepCopy(val), nil
+	}
+
+	var err error
+	result := placeholderRegexp.ReplaceAllStringFunc(s, func(placeholder string) string {
+		name := placeholderRegexp.FindStringSubmatch(placeholder)[1]
+		val, ok := in.lookup(name)
+		if !ok {
+			return placeholder
+		}
+		switch v := val.(type) {
+		case string:
+			return v
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			return strconv.FormatBool(v)
+		case nil:
+			return ""
+		default:
+			if err == nil {
+				err = fmt.Errorf("Variable '%s' can't be interpolated into the middle of the string at pointer '%s', since it isn't a string, number, bool or null",
+					name, JoinPointer(path))
+			}
+			return placeholder
+		}
+	})
+	return result, err
+}
+
+// lookup finds the value of the variable `name`, recording it as missing if
+// it doesn't exist.
+func (in *interpolator) lookup(name string) (interface{}, bool) {
+	var val interface{}
+	found := false
+	if tokens, err := variableTokens(name); err == nil {
+		val, found, err = getByTokens(in.vars.data, tokens)
+		found = found && err == nil
+	}
+	if !found && !in.seen[name] {
+		in.seen[name] = true
+		in.missing = append(in.missing, name)
+	}
+	return val, found
+}
+
+// variableTokens converts a variable name into the reference tokens of the
+// variable's value. Names starting with a '/' are json pointers; other names
+// are separated by dots.
+func variableTokens(name string) ([]string, error) {
+	if strings.HasPrefix(name, "/") {
+		return SplitPointer(name)
+	}
+	return strings.Split(name, "."), nil
+}
@@ -0,0 +1,124 @@
+package unstructured_test
+
+import (
+	"github.com/totherme/unstructured"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Interpolate", func() {
+	var vars unstructured.Data
+
+	BeforeEach(func() {
+		vars = mustParseYAML(`
+deployment_name: my-deployment
+web_instances: 3
+debug: false
+azs: [z1, z2]
+db:
+  host: db.internal
+  port: 5432
+certs:
+- private.key: s3cr3t
+nothing: null
+`)
+	})
+
+	It("replaces whole-value placeholders with the variable's value", func() {
+		result, err := unstructured.Interpolate(mustParseYAML(`
+name: ((deployment_name))
+instances: ((web_instances))
+azs: ((azs))
+debug: (( debug ))
+`), vars)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(mustParseYAML(`
+name: my-deployment
+instances: 3
+azs: [z1, z2]
+debug: false
+`)))
+	})
+
+	It("replaces placeholders inside longer strings with the variable's text", func() {
+		result, err := unstructured.Interpolate(mustParseYAML(`
+url: postgres://((db.host)):((db.port))/((deployment_name))
+flag: --debug=((debug))
+empty: "[((nothing))]"
+`), vars)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(mustParseYAML(`
+url: postgres://db.internal:5432/my-deployment
+flag: --debug=false
+empty: "[]"
+`)))
+	})
+
+	It("finds placeholders anywhere in the tree", func() {
+		result, err := unstructured.Interpolate(mustParseYAML(`
+instance_groups:
+- name: web
+  azs: ((azs))
+  jobs:
+  - properties: {db: ((db))}
+`), vars)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.GetByPointer("/instance_groups/0/azs/1")).To(Equal(mustParseYAML("z2")))
+		Expect(result.GetByPointer("/instance_groups/0/jobs/0/properties/db/port")).To(Equal(mustParseYAML("5432")))
+	})
+
+	It("accepts json pointers as variable names", func() {
+		result, err := unstructured.Interpolate(mustParseYAML(`key: ((/certs/0/private.key))`), vars)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(mustParseYAML(`key: s3cr3t`)))
+	})
+
+	It("leaves the original document and variables alone", func() {
+		doc := mustParseYAML(`{azs: ((azs)), name: ((deployment_name))}`)
+		result, err := unstructured.Interpolate(doc, vars)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.F("azs").SetElem(0, "z3")).To(Succeed())
+		Expect(doc).To(Equal(mustParseYAML(`{azs: ((azs)), name: ((deployment_name))}`)))
+		Expect(vars.F("azs").UnsafeListValue()[0].UnsafeStringValue()).To(Equal("z1"))
+	})
+
+	Context("when variables are missing", func() {
+		var doc unstructured.Data
+
+		BeforeEach(func() {
+			doc = mustParseYAML(`
+name: ((deployment_name))
+password: ((admin_password))
+url: https://((system_domain))/((admin_password))
+port: ((db.missing_port))
+`)
+		})
+
+		It("reports all of them at once", func() {
+			_, err := unstructured.Interpolate(doc, vars)
+			Expect(err).To(MatchError(unstructured.MissingVariablesError{
+				Names: []string{"admin_password", "db.missing_port", "system_domain"},
+			}))
+			Expect(err).To(MatchError("Expected to find variables: admin_password, db.missing_port, system_domain"))
+		})
+
+		It("can leave their placeholders in place", func() {
+			result, err := unstructured.Interpolate(doc, vars, unstructured.AllowMissingVariables())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(mustParseYAML(`
+name: my-deployment
+password: ((admin_password))
+url: https://((system_domain))/((admin_password))
+port: ((db.missing_port))
+`)))
+		})
+	})
+
+	Context("when an inline placeholder refers to an object or list", func() {
+		It("returns an error naming the offending pointer", func() {
+			_, err := unstructured.Interpolate(mustParseYAML(`{list: [ "azs: ((azs))" ]}`), vars)
+			Expect(err).To(MatchError(ContainSubstring("Variable 'azs' can't be interpolated into the middle of the string at pointer '/list/0'")))
+		})
+	})
+})
@@ -0,0 +1,78 @@
+//go:build go1.23
+
+package unstructured
+
+import "iter"
+
+// All returns an iterator over every node in this Data tree, with its json
+// pointer, in the same order as Walk.
+func (j Data) All() iter.Seq2[string, Data] {
+	return func(yield func(string, Data) bool) {
+		allNodes(j.data, nil, yield)
+	}
+}
+
+// allNodes yields `node` and everything below it. Unlike Walk, it never
+// writes to the tree, so several goroutines can iterate over the same Data at
+// once. It returns false if `yield` asked it to stop.
+func allNodes(node interface{}, path []string, yield func(string, Data) bool) bool {
+	if !yield(JoinPointer(path), Data{data: node}) {
+		return false
+	}
+	more := true
+	forEachChild(node, func(token string, child interface{}) {
+		more = more && allNodes(child, appendToken(path, token), yield)
+	})
+	return more
+}
+
+// Fields returns an iterator over the keys and values of the object
+// represented by this Data struct, in sorted key order. If the Data struct
+// does not represent an object, the iterator is empty.
+func (j Data) Fields() iter.Seq2[string, Data] {
+	return func(yield func(string, Data) bool) {
+		jmap, ok := j.data.(map[string]interface{})
+		if !ok {
+			return
+		}
+		for _, key := range sortedKeys(jmap) {
+			if !yield(key, Data{data: jmap[key]}) {
+				return
+			}
+		}
+	}
+}
+
+// Elems returns an iterator over the indexes and elements of the list
+// represented by this Data struct. Unlike ListValue, it doesn't build a slice
+// of every element up front. If the Data struct does not represent a list,
+// the iterator is empty.
+func (j Data) Elems() iter.Seq2[int, Data] {
+	return func(yield func(int, Data) bool) {
+		list, ok := j.data.([]interface{})
+		if !ok {
+			return
+		}
+		for i, elem := range list {
+			if !yield(i, Data{data: elem}) {
+				return
+			}
+		}
+	}
+}
+
+// Leaves returns an iterator over every string, number, bool and null in
+// this Data tree, with its json pointer. Objects and lists are not yielded
+// themselves, only their contents.
+func (j Data) Leaves() iter.Seq2[string, Data] {
+	return func(yield func(string, Data) bool) {
+		for pointer, node := range j.All() {
+			if node.IsOb() || node.IsList() {
+				continue
+			}
+			if !yield(pointer, node) {
+				return
+			}
+		}
+	}
+}
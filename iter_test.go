@@ -0,0 +1,126 @@
+//go:build go1.23
+
+package unstructured_test
+
+import (
+	"sync"
+
+	"github.com/totherme/unstructured"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Iterators", func() {
+	var doc unstructured.Data
+
+	BeforeEach(func() {
+		var err error
+		doc, err = unstructured.ParseYAML(`
+name: web
+jobs:
+- name: nginx
+- name: debug
+tags: {}
+enabled: true
+`)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Describe("All", func() {
+		It("yields every node with its pointer", func() {
+			var pointers []string
+			for pointer, node := range doc.All() {
+				Expect(doc.GetByPointer(pointer)).To(Equal(node))
+				pointers = append(pointers, pointer)
+			}
+			Expect(pointers).To(Equal([]string{
+				"", "/enabled", "/jobs", "/jobs/0", "/jobs/0/name", "/jobs/1", "/jobs/1/name", "/name", "/tags",
+			}))
+		})
+
+		It("stops when the loop breaks", func() {
+			count := 0
+			for range doc.All() {
+				count++
+				if count == 3 {
+					break
+				}
+			}
+			Expect(count).To(Equal(3))
+		})
+	})
+
+	Describe("Fields", func() {
+		It("yields the fields of an object in sorted order", func() {
+			var keys []string
+			for key, val := range doc.Fields() {
+				Expect(val).To(Equal(doc.F(key)))
+				keys = append(keys, key)
+			}
+			Expect(keys).To(Equal([]string{"enabled", "jobs", "name", "tags"}))
+		})
+
+		It("yields nothing for things which aren't objects", func() {
+			for range doc.F("jobs").Fields() {
+				Fail("a list has no fields")
+			}
+		})
+	})
+
+	Describe("Elems", func() {
+		It("yields the elements of a list with their indexes", func() {
+			var names []string
+			for i, elem := range doc.F("jobs").Elems() {
+				Expect(elem).To(Equal(doc.F("jobs").UnsafeListValue()[i]))
+				names = append(names, elem.F("name").UnsafeStringValue())
+			}
+			Expect(names).To(Equal([]string{"nginx", "debug"}))
+		})
+
+		It("yields nothing for things which aren't lists", func() {
+			for range doc.Elems() {
+				Fail("an object has no elements")
+			}
+		})
+	})
+
+	Describe("Leaves", func() {
+		It("yields only the scalars in the tree", func() {
+			leaves := map[string]interface{}{}
+			for pointer, leaf := range doc.Leaves() {
+				leaves[pointer] = leaf.RawValue()
+			}
+			Expect(leaves).To(Equal(map[string]interface{}{
+				"/enabled":     true,
+				"/jobs/0/name": "nginx",
+				"/jobs/1/name": "debug",
+				"/name":        "web",
+			}))
+		})
+
+		It("stops when the loop breaks", func() {
+			for pointer := range doc.Leaves() {
+				Expect(pointer).To(Equal("/enabled"))
+				break
+			}
+		})
+
+		It("can be used from several goroutines at once", func() {
+			var wg sync.WaitGroup
+			for i := 0; i < 4; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					defer GinkgoRecover()
+					count := 0
+					for range doc.Leaves() {
+						count++
+					}
+					Expect(count).To(Equal(4))
+				}()
+			}
+			wg.Wait()
+		})
+	})
+})
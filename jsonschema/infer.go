@@ -0,0 +1,188 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+
+	"github.com/totherme/unstructured"
+)
+
+// Dialect is the `$schema` of the schemas which InferSchema produces.
+const Dialect = "https://json-schema.org/draft/2020-12/schema"
+
+// maxEnumValues is the largest number of distinct strings InferSchema will
+// turn into an enum.
+const maxEnumValues = 5
+
+// dataTypes lists the kinds of unstructured data in the order InferSchema lists
+// them in a schema.
+var dataTypes = []unstructured.Kind{
+	unstructured.KindOb,
+	unstructured.KindList,
+	unstructured.KindString,
+	unstructured.KindNum,
+	unstructured.KindBool,
+	unstructured.KindNull,
+}
+
+// InferSchema produces a JSON Schema which accepts all of `samples`.
+//
+// Wherever the samples have values of more than one type, the schema allows
+// all of those types. Object keys which appear in every sample object are
+// required, and the items of lists are described by a single schema covering
+// every item seen. Numbers which are always whole become integers. Strings
+// become enums if only a few distinct values are seen, each of them at least
+// twice.
+func InferSchema(samples ...unstructured.Data) unstructured.Data {
+	root := newObservation()
+	for _, sample := range samples {
+		root.observe(sample)
+	}
+	schema := root.schema()
+	schema["$schema"] = Dialect
+
+	encoded, err := json.Marshal(schema)
+	if err != nil {
+		panic("an inferred schema should always be valid json")
+	}
+	data, err := unstructured.ParseJSON(string(encoded))
+	if err != nil {
+		panic("an inferred schema should always be valid json")
+	}
+	return data
+}
+
+// observation summarises every value seen at one place in the samples.
+type observation struct {
+	types      map[unstructured.Kind]bool
+	nonInteger bool
+	strings    map[string]int
+	objects    int
+	properties map[string]*observation
+	propCounts map[string]int
+	items      *observation
+}
+
+func newObservation() *observation {
+	return &observation{
+		types:      map[unstructured.Kind]bool{},
+		strings:    map[string]int{},
+		properties: map[string]*observation{},
+		propCounts: map[string]int{},
+	}
+}
+
+func (o *observation) observe(d unstructured.Data) {
+	if typ := d.Type(); typ != "" {
+		o.types[typ] = true
+	}
+
+	switch {
+	case d.IsOb():
+		o.objects++
+		for key := range d.UnsafeObValue() {
+			if o.properties[key] == nil {
+				o.properties[key] = newObservation()
+			}
+			o.propCounts[key]++
+			o.properties[key].observe(d.F(key))
+		}
+	case d.IsList():
+		for _, item := range d.UnsafeListValue() {
+			if o.items == nil {
+				o.items = newObservation()
+			}
+			o.items.observe(item)
+		}
+	case d.IsString():
+		o.strings[d.UnsafeStringValue()]++
+	case d.IsNum():
+		num := d.UnsafeNumValue()
+		if num != math.Trunc(num) {
+			o.nonInteger = true
+		}
+	}
+}
+
+func (o *observation) schema() map[string]interface{} {
+	schema := map[string]interface{}{}
+
+	var types []interface{}
+	for _, typ := range dataTypes {
+		if o.types[typ] {
+			types = append(types, o.schemaTypeName(typ))
+		}
+	}
+	switch len(types) {
+	case 0:
+		return schema
+	case 1:
+		schema["type"] = types[0]
+	default:
+		schema["type"] = types
+	}
+
+	if o.types[unstructured.KindOb] {
+		properties := map[string]interface{}{}
+		required := []string{}
+		for key, prop := range o.properties {
+			properties[key] = prop.schema()
+			if o.propCounts[key] == o.objects {
+				required = append(required, key)
+			}
+		}
+		sort.Strings(required)
+		if len(properties) > 0 {
+			schema["properties"] = properties
+		}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+	}
+
+	if o.items != nil {
+		schema["items"] = o.items.schema()
+	}
+
+	if len(types) == 1 && o.types[unstructured.KindString] && o.isEnum() {
+		enum := []string{}
+		for val := range o.strings {
+			enum = append(enum, val)
+		}
+		sort.Strings(enum)
+		schema["enum"] = enum
+	}
+
+	return schema
+}
+
+func (o *observation) isEnum() bool {
+	if len(o.strings) > maxEnumValues {
+		return false
+	}
+	for _, count := range o.strings {
+		if count < 2 {
+			return false
+		}
+	}
+	return true
+}
+
+func (o *observation) schemaTypeName(typ unstructured.Kind) string {
+	switch typ {
+	case unstructured.KindOb:
+		return "object"
+	case unstructured.KindList:
+		return "array"
+	case unstructured.KindNum:
+		if o.nonInteger {
+			return "number"
+		}
+		return "integer"
+	case unstructured.KindBool:
+		return "boolean"
+	default:
+		return string(typ)
+	}
+}
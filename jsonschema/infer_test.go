@@ -0,0 +1,83 @@
+package jsonschema_test
+
+import (
+	"github.com/totherme/unstructured/jsonschema"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("InferSchema", func() {
+	It("describes the types, required keys and list items of the samples", func() {
+		schema := jsonschema.InferSchema(
+			mustParseYAML(`
+name: web
+instances: 2
+azs: [z1, z2]
+env: prod
+`),
+			mustParseYAML(`
+name: db
+instances: 1.5
+azs: [z1]
+env: prod
+persistent: true
+`),
+			mustParseYAML(`
+name: worker
+instances: 3
+azs: []
+env: dev
+persistent: null
+`),
+			mustParseYAML(`
+name: cache
+instances: 1
+azs: [z2]
+env: dev
+`),
+		)
+
+		Expect(schema).To(Equal(mustParseYAML(`
+$schema: https://json-schema.org/draft/2020-12/schema
+type: object
+required: [azs, env, instances, name]
+properties:
+  name: {type: string}
+  instances: {type: number}
+  azs:
+    type: array
+    items: {type: string, enum: [z1, z2]}
+  env:
+    type: string
+    enum: [dev, prod]
+  persistent:
+    type: [boolean, "null"]
+`)))
+	})
+
+	It("infers integers when every number is whole", func() {
+		schema := jsonschema.InferSchema(mustParseYAML("1"), mustParseYAML("7"))
+		Expect(schema.F("type")).To(Equal(mustParseYAML("integer")))
+	})
+
+	It("allows every type seen in a union", func() {
+		schema := jsonschema.InferSchema(mustParseYAML("[1, a, {b: c}]"))
+		Expect(schema.F("items").F("type")).To(Equal(mustParseYAML("[object, string, integer]")))
+		Expect(schema.F("items").F("required")).To(Equal(mustParseYAML("[b]")))
+	})
+
+	It("produces schemas which accept the samples", func() {
+		samples := []string{`{a: 1, b: [x, y]}`, `{a: 2.5, b: [], c: null}`}
+		schema := jsonschema.InferSchema(mustParseYAML(samples[0]), mustParseYAML(samples[1]))
+		for _, sample := range samples {
+			violations, err := jsonschema.Validate(schema, mustParseYAML(sample))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(violations).To(BeEmpty())
+		}
+	})
+
+	It("accepts anything when there are no samples", func() {
+		Expect(jsonschema.InferSchema()).To(Equal(mustParseYAML(`$schema: https://json-schema.org/draft/2020-12/schema`)))
+	})
+})
@@ -0,0 +1,347 @@
+// Package jsonschema validates unstructured data against JSON Schemas.
+//
+// Schemas are themselves unstructured.Data, so they can be written in either
+// JSON or YAML. Both draft 2020-12 and draft-07 schemas are supported: a
+// schema is treated as draft-07 if its `$schema` says so, and as draft 2020-12
+// otherwise, except that a list of `items`, which 2020-12 doesn't allow, is
+// always read the draft-07 way. Validation is entirely offline, so every
+// `$ref` must point somewhere inside the schema itself -- either by json
+// pointer, by anchor, or by the `$id` of a subschema.
+//
+// The `format` keyword is treated as an annotation, and never causes a
+// violation. InferSchema works in the other direction, producing a schema from
+// sample documents. For more information on JSON Schema, see
+// https://json-schema.org/specification
+package jsonschema
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/totherme/unstructured"
+)
+
+// A Violation describes one way in which a document fails to conform to a
+// schema.
+type Violation struct {
+	// InstancePointer is the json pointer of the offending value in the
+	// document.
+	InstancePointer string
+	// SchemaPointer is the json pointer of the keyword in the schema which
+	// the value violates.
+	SchemaPointer string
+	// Message describes the problem.
+	Message string
+}
+
+func (v Violation) Error() string {
+	return fmt.Sprintf("at '%s': %s (schema '%s')", v.InstancePointer, v.Message, v.SchemaPointer)
+}
+
+// Schema is a compiled JSON Schema, ready to validate documents.
+type Schema struct {
+	root     interface{}
+	draft07  bool
+	nodes    map[string]interface{}
+	refs     map[string]string
+	patterns map[string]*regexp.Regexp
+}
+
+// Validate compiles `schema` and validates `doc` against it. It returns an
+// error only if the schema itself is invalid: a document which doesn't
+// conform to the schema results in a list of violations.
+func Validate(schema unstructured.Data, doc unstructured.Data) ([]Violation, error) {
+	compiled, err := Compile(schema)
+	if err != nil {
+		return nil, err
+	}
+	return compiled.Validate(doc), nil
+}
+
+// Compile checks that `schema` is a usable JSON Schema, and resolves all of
+// its references, so that it can validate documents quickly.
+func Compile(schema unstructured.Data) (*Schema, error) {
+	s := &Schema{
+		root:     schema.RawValue(),
+		nodes:    map[string]interface{}{},
+		refs:     map[string]string{},
+		patterns: map[string]*regexp.Regexp{},
+	}
+	if schema.IsOb() && schema.HasKey("$schema") {
+		dialect, _ := schema.F("$schema").StringValue()
+		s.draft07 = strings.Contains(dialect, "draft-07") || strings.Contains(dialect, "draft-06")
+	}
+
+	c := &compiler{schema: s, resources: map[string]string{}}
+	if err := c.collect(s.root, nil, &url.URL{}); err != nil {
+		return nil, err
+	}
+	for _, ref := range c.pendingRefs {
+		target, err := c.resolve(ref.target)
+		if err != nil {
+			return nil, fmt.Errorf("Can't resolve $ref '%s' at '%s': %s", ref.raw, ref.pointer, err)
+		}
+		s.refs[ref.pointer] = target
+	}
+	if err := c.checkRefCycles(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+type compiler struct {
+	schema      *Schema
+	resources   map[string]string
+	pendingRefs []pendingRef
+}
+
+type pendingRef struct {
+	pointer string
+	raw     string
+	target  *url.URL
+}
+
+// schemaKeywords lists the keywords whose values are subschemas.
+var schemaKeywords = []string{
+	"additionalProperties", "propertyNames", "contains", "not", "if", "then", "else",
+	"items", "additionalItems", "unevaluatedProperties", "unevaluatedItems",
+}
+
+// schemaMapKeywords lists the keywords whose values are objects of
+// subschemas.
+var schemaMapKeywords = []string{
+	"properties", "patternProperties", "$defs", "definitions", "dependentSchemas", "dependencies",
+}
+
+// schemaListKeywords lists the keywords whose values are lists of
+// subschemas. In draft-07, `items` may be either a subschema or a list.
+var schemaListKeywords = []string{
+	"allOf", "anyOf", "oneOf", "prefixItems", "items",
+}
+
+// collect walks every subschema, recording where it lives, the resources and
+// anchors it defines, and the references it makes.
+func (c *compiler) collect(node interface{}, path []string, base *url.URL) error {
+	pointer := unstructured.JoinPointer(path)
+	switch n := node.(type) {
+	case bool:
+		c.schema.nodes[pointer] = n
+		return nil
+	case map[string]interface{}:
+		c.schema.nodes[pointer] = n
+		if path == nil {
+			c.resources[""] = ""
+		}
+		if id, ok := n["$id"].(string); ok {
+			idURL, err := url.Parse(id)
+			if err != nil {
+				return fmt.Errorf("Invalid $id '%s' at '%s': %s", id, pointer, err)
+			}
+			resolved := base.ResolveReference(idURL)
+			if strings.HasPrefix(id, "#") {
+				c.resources[resolved.String()] = pointer
+			} else {
+				base = withoutFragment(resolved)
+				c.resources[base.String()] = pointer
+			}
+		}
+		if anchor, ok := n["$anchor"].(string); ok {
+			anchored := *base
+			anchored.Fragment = anchor
+			c.resources[anchored.String()] = pointer
+		}
+		if ref, ok := n["$ref"].(string); ok {
+			refURL, err := url.Parse(ref)
+			if err != nil {
+				return fmt.Errorf("Invalid $ref '%s' at '%s': %s", ref, pointer, err)
+			}
+			c.pendingRefs = append(c.pendingRefs, pendingRef{pointer: pointer, raw: ref, target: base.ResolveReference(refURL)})
+		}
+		if pattern, ok := n["pattern"].(string); ok {
+			if err := c.compilePattern(pattern, pointer); err != nil {
+				return err
+			}
+		}
+		if patterns, ok := n["patternProperties"].(map[string]interface{}); ok {
+			for pattern := range patterns {
+				if err := c.compilePattern(pattern, pointer); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, key := range schemaKeywords {
+			if child, ok := n[key]; ok {
+				if _, isList := child.([]interface{}); isList && key == "items" {
+					continue
+				}
+				if err := c.collect(child, appendToken(path, key), base); err != nil {
+					return err
+				}
+			}
+		}
+		for _, key := range schemaMapKeywords {
+			if children, ok := n[key].(map[string]interface{}); ok {
+				for name, child := range children {
+					if err := c.collect(child, appendToken(appendToken(path, key), name), base); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		for _, key := range schemaListKeywords {
+			if children, ok := n[key].([]interface{}); ok {
+				for i, child := range children {
+					if err := c.collect(child, appendToken(appendToken(path, key), strconv.Itoa(i)), base); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	case []interface{}:
+		// Lists only appear as schemas in draft-07 `dependencies`, where
+		// they list required properties rather than being schemas.
+		if len(path) >= 2 && path[len(path)-2] == "dependencies" {
+			return nil
+		}
+	}
+	return fmt.Errorf("Invalid schema at '%s': a schema must be an object or a bool", pointer)
+}
+
+func (c *compiler) compilePattern(pattern string, pointer string) error {
+	if _, ok := c.schema.patterns[pattern]; ok {
+		return nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("Invalid pattern '%s' at '%s': %s", pattern, pointer, err)
+	}
+	c.schema.patterns[pattern] = re
+	return nil
+}
+
+// resolve finds the schema pointer which a resolved $ref refers to.
+func (c *compiler) resolve(target *url.URL) (string, error) {
+	if pointer, ok := c.resources[target.String()]; ok {
+		return pointer, nil
+	}
+	resource, ok := c.resources[withoutFragment(target).String()]
+	if !ok {
+		return "", fmt.Errorf("it isn't in this schema, and remote references aren't supported")
+	}
+	if target.Fragment != "" && !strings.HasPrefix(target.Fragment, "/") {
+		return "", fmt.Errorf("there's no anchor called '%s'", target.Fragment)
+	}
+	pointer := resource + target.Fragment
+	if _, ok := c.schema.nodes[pointer]; !ok {
+		return "", fmt.Errorf("there's no schema at '%s'", pointer)
+	}
+	return pointer, nil
+}
+
+// inPlaceKeywords lists the keywords whose subschemas apply to the same value
+// as the schema they're in, rather than to one of its properties or items.
+var inPlaceKeywords = []string{"not", "if", "then", "else"}
+
+// inPlaceListKeywords and inPlaceMapKeywords are the same, for keywords
+// whose values are lists or objects of subschemas.
+var (
+	inPlaceListKeywords = []string{"allOf", "anyOf", "oneOf"}
+	inPlaceMapKeywords  = []string{"dependentSchemas", "dependencies"}
+)
+
+// checkRefCycles makes sure that no chain of references leads from a schema
+// back to itself while validating the same value, since validating anything
+// against such a schema would never finish.
+func (c *compiler) checkRefCycles() error {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := map[string]int{}
+	var visit func(pointer string) error
+	visit = func(pointer string) error {
+		switch state[pointer] {
+		case visiting:
+			return fmt.Errorf("Invalid schema at '%s': its $ref leads back to it without descending into the document", pointer)
+		case done:
+			return nil
+		}
+		state[pointer] = visiting
+		for _, next := range c.inPlaceChildren(pointer) {
+			if err := visit(next); err != nil {
+				return err
+			}
+		}
+		state[pointer] = done
+		return nil
+	}
+	pointers := make([]string, 0, len(c.schema.refs))
+	for pointer := range c.schema.refs {
+		pointers = append(pointers, pointer)
+	}
+	sort.Strings(pointers)
+	for _, pointer := range pointers {
+		if err := visit(pointer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inPlaceChildren returns the pointers of the schemas which the schema at
+// `pointer` applies to the same value as itself, including its $ref.
+func (c *compiler) inPlaceChildren(pointer string) []string {
+	n, ok := c.schema.nodes[pointer].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	var children []string
+	if target, ok := c.schema.refs[pointer]; ok {
+		children = append(children, target)
+	}
+	for _, key := range inPlaceKeywords {
+		if _, ok := n[key]; ok {
+			children = append(children, pointer+unstructured.JoinPointer([]string{key}))
+		}
+	}
+	for _, key := range inPlaceListKeywords {
+		if list, ok := n[key].([]interface{}); ok {
+			for i := range list {
+				children = append(children, pointer+unstructured.JoinPointer([]string{key, strconv.Itoa(i)}))
+			}
+		}
+	}
+	for _, key := range inPlaceMapKeywords {
+		if ob, ok := n[key].(map[string]interface{}); ok {
+			names := make([]string, 0, len(ob))
+			for name := range ob {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				children = append(children, pointer+unstructured.JoinPointer([]string{key, name}))
+			}
+		}
+	}
+	return children
+}
+
+func withoutFragment(u *url.URL) *url.URL {
+	stripped := *u
+	stripped.Fragment = ""
+	stripped.RawFragment = ""
+	return &stripped
+}
+
+func appendToken(path []string, token string) []string {
+	newPath := make([]string, len(path), len(path)+1)
+	copy(newPath, path)
+	return append(newPath, token)
+}
@@ -0,0 +1,456 @@
+package jsonschema
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/totherme/unstructured"
+)
+
+// Validate validates `doc` against this schema, and returns every violation
+// it finds. A document which conforms to the schema has no violations.
+func (s *Schema) Validate(doc unstructured.Data) []Violation {
+	return s.validate(doc.RawValue(), nil, s.root, nil).violations
+}
+
+// result is the outcome of validating an instance against a schema: the
+// violations found, and which properties and items of the instance were
+// evaluated, for the benefit of unevaluatedProperties and unevaluatedItems.
+type result struct {
+	violations []Violation
+	props      map[string]bool
+	items      map[int]bool
+}
+
+func (r *result) fail(instPath []string, schemaPath []string, keyword string, format string, args ...interface{}) {
+	r.violations = append(r.violations, Violation{
+		InstancePointer: unstructured.JoinPointer(instPath),
+		SchemaPointer:   unstructured.JoinPointer(appendToken(schemaPath, keyword)),
+		Message:         fmt.Sprintf(format, args...),
+	})
+}
+
+// absorb adds the violations of a subschema to this result, along with its
+// annotations if it succeeded.
+func (r *result) absorb(sub result) {
+	r.violations = append(r.violations, sub.violations...)
+	if len(sub.violations) == 0 {
+		r.absorbAnnotations(sub)
+	}
+}
+
+func (r *result) absorbAnnotations(sub result) {
+	for prop := range sub.props {
+		r.props[prop] = true
+	}
+	for item := range sub.items {
+		r.items[item] = true
+	}
+}
+
+func (r result) ok() bool {
+	return len(r.violations) == 0
+}
+
+func (s *Schema) validate(inst interface{}, instPath []string, schema interface{}, schemaPath []string) result {
+	r := result{props: map[string]bool{}, items: map[int]bool{}}
+	switch sch := schema.(type) {
+	case bool:
+		if !sch {
+			r.violations = append(r.violations, Violation{
+				InstancePointer: unstructured.JoinPointer(instPath),
+				SchemaPointer:   unstructured.JoinPointer(schemaPath),
+				Message:         "no value is allowed here",
+			})
+		}
+		return r
+	case map[string]interface{}:
+		if _, ok := sch["$ref"].(string); ok {
+			target := s.refs[unstructured.JoinPointer(schemaPath)]
+			// Compile only resolves references to pointers it has built
+			// itself, so the target is always a valid pointer.
+			targetPath, _ := unstructured.SplitPointer(target)
+			r.absorb(s.validate(inst, instPath, s.nodes[target], targetPath))
+			if s.draft07 {
+				return r
+			}
+		}
+		s.validateGeneric(&r, inst, instPath, sch, schemaPath)
+		s.validateCombinators(&r, inst, instPath, sch, schemaPath)
+		switch i := inst.(type) {
+		case float64:
+			s.validateNumber(&r, i, instPath, sch, schemaPath)
+		case string:
+			s.validateString(&r, i, instPath, sch, schemaPath)
+		case []interface{}:
+			s.validateList(&r, i, instPath, sch, schemaPath)
+		case map[string]interface{}:
+			s.validateObject(&r, i, instPath, sch, schemaPath)
+		}
+	}
+	return r
+}
+
+func (s *Schema) validateGeneric(r *result, inst interface{}, instPath []string, sch map[string]interface{}, schemaPath []string) {
+	if typ, ok := sch["type"]; ok {
+		var types []string
+		switch t := typ.(type) {
+		case string:
+			types = []string{t}
+		case []interface{}:
+			for _, elem := range t {
+				if name, ok := elem.(string); ok {
+					types = append(types, name)
+				}
+			}
+		}
+		matched := false
+		for _, t := range types {
+			if hasType(inst, t) {
+				matched = true
+			}
+		}
+		if !matched {
+			r.fail(instPath, schemaPath, "type", "expected %s, got %s", strings.Join(types, " or "), typeName(inst))
+		}
+	}
+
+	if enum, ok := sch["enum"].([]interface{}); ok {
+		found := false
+		for _, val := range enum {
+			if reflect.DeepEqual(inst, val) {
+				found = true
+			}
+		}
+		if !found {
+			r.fail(instPath, schemaPath, "enum", "%s is not one of the allowed values", describe(inst))
+		}
+	}
+
+	if val, ok := sch["const"]; ok && !reflect.DeepEqual(inst, val) {
+		r.fail(instPath, schemaPath, "const", "%s is not the required constant value", describe(inst))
+	}
+}
+
+func (s *Schema) validateCombinators(r *result, inst interface{}, instPath []string, sch map[string]interface{}, schemaPath []string) {
+	if allOf, ok := sch["allOf"].([]interface{}); ok {
+		for i, sub := range allOf {
+			r.absorb(s.validate(inst, instPath, sub, appendToken(appendToken(schemaPath, "allOf"), strconv.Itoa(i))))
+		}
+	}
+
+	if anyOf, ok := sch["anyOf"].([]interface{}); ok {
+		matched := false
+		for i, sub := range anyOf {
+			subResult := s.validate(inst, instPath, sub, appendToken(appendToken(schemaPath, "anyOf"), strconv.Itoa(i)))
+			if subResult.ok() {
+				matched = true
+				r.absorbAnnotations(subResult)
+			}
+		}
+		if !matched {
+			r.fail(instPath, schemaPath, "anyOf", "doesn't match any of the allowed schemas")
+		}
+	}
+
+	if oneOf, ok := sch["oneOf"].([]interface{}); ok {
+		var matches []string
+		for i, sub := range oneOf {
+			subResult := s.validate(inst, instPath, sub, appendToken(appendToken(schemaPath, "oneOf"), strconv.Itoa(i)))
+			if subResult.ok() {
+				matches = append(matches, strconv.Itoa(i))
+				r.absorbAnnotations(subResult)
+			}
+		}
+		if len(matches) == 0 {
+			r.fail(instPath, schemaPath, "oneOf", "doesn't match any of the allowed schemas")
+		} else if len(matches) > 1 {
+			r.fail(instPath, schemaPath, "oneOf", "matches more than one of the allowed schemas (%s)", strings.Join(matches, ", "))
+		}
+	}
+
+	if not, ok := sch["not"]; ok {
+		if s.validate(inst, instPath, not, appendToken(schemaPath, "not")).ok() {
+			r.fail(instPath, schemaPath, "not", "matches a schema which it must not match")
+		}
+	}
+
+	if ifSchema, ok := sch["if"]; ok {
+		ifResult := s.validate(inst, instPath, ifSchema, appendToken(schemaPath, "if"))
+		if ifResult.ok() {
+			r.absorbAnnotations(ifResult)
+			if then, ok := sch["then"]; ok {
+				r.absorb(s.validate(inst, instPath, then, appendToken(schemaPath, "then")))
+			}
+		} else if elseSchema, ok := sch["else"]; ok {
+			r.absorb(s.validate(inst, instPath, elseSchema, appendToken(schemaPath, "else")))
+		}
+	}
+}
+
+func (s *Schema) validateNumber(r *result, num float64, instPath []string, sch map[string]interface{}, schemaPath []string) {
+	if multipleOf, ok := sch["multipleOf"].(float64); ok && multipleOf > 0 {
+		quotient := num / multipleOf
+		if math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+			r.fail(instPath, schemaPath, "multipleOf", "%s is not a multiple of %s", describe(num), describe(multipleOf))
+		}
+	}
+	if max, ok := sch["maximum"].(float64); ok && num > max {
+		r.fail(instPath, schemaPath, "maximum", "%s is greater than the maximum %s", describe(num), describe(max))
+	}
+	if max, ok := sch["exclusiveMaximum"].(float64); ok && num >= max {
+		r.fail(instPath, schemaPath, "exclusiveMaximum", "%s is not less than %s", describe(num), describe(max))
+	}
+	if min, ok := sch["minimum"].(float64); ok && num < min {
+		r.fail(instPath, schemaPath, "minimum", "%s is less than the minimum %s", describe(num), describe(min))
+	}
+	if min, ok := sch["exclusiveMinimum"].(float64); ok && num <= min {
+		r.fail(instPath, schemaPath, "exclusiveMinimum", "%s is not greater than %s", describe(num), describe(min))
+	}
+}
+
+func (s *Schema) validateString(r *result, str string, instPath []string, sch map[string]interface{}, schemaPath []string) {
+	length := utf8.RuneCountInString(str)
+	if max, ok := sch["maxLength"].(float64); ok && float64(length) > max {
+		r.fail(instPath, schemaPath, "maxLength", "is %d characters long, which is more than %s", length, describe(max))
+	}
+	if min, ok := sch["minLength"].(float64); ok && float64(length) < min {
+		r.fail(instPath, schemaPath, "minLength", "is %d characters long, which is fewer than %s", length, describe(min))
+	}
+	if pattern, ok := sch["pattern"].(string); ok && !s.patterns[pattern].MatchString(str) {
+		r.fail(instPath, schemaPath, "pattern", "%s doesn't match the pattern '%s'", describe(str), pattern)
+	}
+}
+
+func (s *Schema) validateList(r *result, list []interface{}, instPath []string, sch map[string]interface{}, schemaPath []string) {
+	if max, ok := sch["maxItems"].(float64); ok && float64(len(list)) > max {
+		r.fail(instPath, schemaPath, "maxItems", "has %d items, which is more than %s", len(list), describe(max))
+	}
+	if min, ok := sch["minItems"].(float64); ok && float64(len(list)) < min {
+		r.fail(instPath, schemaPath, "minItems", "has %d items, which is fewer than %s", len(list), describe(min))
+	}
+	if unique, ok := sch["uniqueItems"].(bool); ok && unique {
+	duplicates:
+		for i := range list {
+			for j := i + 1; j < len(list); j++ {
+				if reflect.DeepEqual(list[i], list[j]) {
+					r.fail(instPath, schemaPath, "uniqueItems", "items %d and %d are the same", i, j)
+					break duplicates
+				}
+			}
+		}
+	}
+
+	itemPath := func(i int) []string {
+		return appendToken(instPath, strconv.Itoa(i))
+	}
+
+	// Positional items: prefixItems in 2020-12, or a list of items in draft-07.
+	// A list of items only means something in draft-07, so it's read that way
+	// even in a schema which doesn't say which draft it is.
+	prefixKeyword := "prefixItems"
+	if _, isList := sch["items"].([]interface{}); isList || s.draft07 {
+		prefixKeyword = "items"
+	}
+	prefix, _ := sch[prefixKeyword].([]interface{})
+	for i, sub := range prefix {
+		if i >= len(list) {
+			break
+		}
+		r.absorb(s.validate(list[i], itemPath(i), sub, appendToken(appendToken(schemaPath, prefixKeyword), strconv.Itoa(i))))
+		r.items[i] = true
+	}
+
+	// The rest of the items: items in 2020-12, or additionalItems (or a
+	// single items schema) in draft-07.
+	restKeyword := "items"
+	if prefixKeyword == "items" && prefix != nil {
+		restKeyword = "additionalItems"
+	}
+	if rest, ok := sch[restKeyword]; ok {
+		if _, isList := rest.([]interface{}); !isList {
+			for i := len(prefix); i < len(list); i++ {
+				r.absorb(s.validate(list[i], itemPath(i), rest, appendToken(schemaPath, restKeyword)))
+				r.items[i] = true
+			}
+		}
+	}
+
+	if contains, ok := sch["contains"]; ok {
+		matches := 0
+		for i, item := range list {
+			if s.validate(item, itemPath(i), contains, appendToken(schemaPath, "contains")).ok() {
+				matches++
+				r.items[i] = true
+			}
+		}
+		min := 1.0
+		if m, ok := sch["minContains"].(float64); ok && !s.draft07 {
+			min = m
+		}
+		if float64(matches) < min {
+			r.fail(instPath, schemaPath, "contains", "has %d items matching the contains schema, which is fewer than %s", matches, describe(min))
+		}
+		if max, ok := sch["maxContains"].(float64); ok && !s.draft07 && float64(matches) > max {
+			r.fail(instPath, schemaPath, "maxContains", "has %d items matching the contains schema, which is more than %s", matches, describe(max))
+		}
+	}
+
+	if unevaluated, ok := sch["unevaluatedItems"]; ok && !s.draft07 {
+		for i, item := range list {
+			if !r.items[i] {
+				r.absorb(s.validate(item, itemPath(i), unevaluated, appendToken(schemaPath, "unevaluatedItems")))
+				r.items[i] = true
+			}
+		}
+	}
+}
+
+func (s *Schema) validateObject(r *result, ob map[string]interface{}, instPath []string, sch map[string]interface{}, schemaPath []string) {
+	keys := make([]string, 0, len(ob))
+	for key := range ob {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	if max, ok := sch["maxProperties"].(float64); ok && float64(len(ob)) > max {
+		r.fail(instPath, schemaPath, "maxProperties", "has %d properties, which is more than %s", len(ob), describe(max))
+	}
+	if min, ok := sch["minProperties"].(float64); ok && float64(len(ob)) < min {
+		r.fail(instPath, schemaPath, "minProperties", "has %d properties, which is fewer than %s", len(ob), describe(min))
+	}
+	if required, ok := sch["required"].([]interface{}); ok {
+		for _, name := range required {
+			if key, ok := name.(string); ok {
+				if _, present := ob[key]; !present {
+					r.fail(instPath, schemaPath, "required", "is missing the required property '%s'", key)
+				}
+			}
+		}
+	}
+
+	dependentRequired, _ := sch["dependentRequired"].(map[string]interface{})
+	dependentSchemas, _ := sch["dependentSchemas"].(map[string]interface{})
+	dependenciesKeyword, dependentSchemasKeyword := "dependentRequired", "dependentSchemas"
+	if s.draft07 {
+		dependencies, _ := sch["dependencies"].(map[string]interface{})
+		dependentRequired, dependentSchemas = map[string]interface{}{}, map[string]interface{}{}
+		for key, dependency := range dependencies {
+			if _, isList := dependency.([]interface{}); isList {
+				dependentRequired[key] = dependency
+			} else {
+				dependentSchemas[key] = dependency
+			}
+		}
+		dependenciesKeyword, dependentSchemasKeyword = "dependencies", "dependencies"
+	}
+	for _, key := range keys {
+		if deps, ok := dependentRequired[key].([]interface{}); ok {
+			for _, dep := range deps {
+				if depKey, ok := dep.(string); ok {
+					if _, present := ob[depKey]; !present {
+						r.fail(instPath, schemaPath, dependenciesKeyword, "has the property '%s', so it must also have '%s'", key, depKey)
+					}
+				}
+			}
+		}
+		if sub, ok := dependentSchemas[key]; ok {
+			r.absorb(s.validate(ob, instPath, sub, appendToken(appendToken(schemaPath, dependentSchemasKeyword), key)))
+		}
+	}
+
+	if propertyNames, ok := sch["propertyNames"]; ok {
+		for _, key := range keys {
+			r.absorb(s.validate(key, appendToken(instPath, key), propertyNames, appendToken(schemaPath, "propertyNames")))
+		}
+	}
+
+	properties, _ := sch["properties"].(map[string]interface{})
+	patternProperties, _ := sch["patternProperties"].(map[string]interface{})
+	additional, hasAdditional := sch["additionalProperties"]
+	for _, key := range keys {
+		matched := false
+		if sub, ok := properties[key]; ok {
+			matched = true
+			r.absorb(s.validate(ob[key], appendToken(instPath, key), sub, appendToken(appendToken(schemaPath, "properties"), key)))
+		}
+		for pattern, sub := range patternProperties {
+			if s.patterns[pattern].MatchString(key) {
+				matched = true
+				r.absorb(s.validate(ob[key], appendToken(instPath, key), sub, appendToken(appendToken(schemaPath, "patternProperties"), pattern)))
+			}
+		}
+		if !matched && hasAdditional {
+			matched = true
+			r.absorb(s.validate(ob[key], appendToken(instPath, key), additional, appendToken(schemaPath, "additionalProperties")))
+		}
+		if matched {
+			r.props[key] = true
+		}
+	}
+
+	if unevaluated, ok := sch["unevaluatedProperties"]; ok && !s.draft07 {
+		for _, key := range keys {
+			if !r.props[key] {
+				r.absorb(s.validate(ob[key], appendToken(instPath, key), unevaluated, appendToken(schemaPath, "unevaluatedProperties")))
+				r.props[key] = true
+			}
+		}
+	}
+}
+
+func hasType(inst interface{}, typ string) bool {
+	switch typ {
+	case "integer":
+		num, ok := inst.(float64)
+		return ok && num == math.Trunc(num)
+	case "number":
+		_, ok := inst.(float64)
+		return ok
+	default:
+		return typeName(inst) == typ
+	}
+}
+
+// typeName returns the JSON Schema name for the type of a raw value.
+func typeName(inst interface{}) string {
+	switch inst.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", inst)
+	}
+}
+
+// describe gives a short description of a raw value for violation messages.
+func describe(val interface{}) string {
+	switch v := val.(type) {
+	case nil:
+		return "null"
+	case string:
+		return strconv.Quote(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []interface{}:
+		return "an array"
+	case map[string]interface{}:
+		return "an object"
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
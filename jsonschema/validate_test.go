@@ -0,0 +1,250 @@
+package jsonschema_test
+
+import (
+	"github.com/totherme/unstructured"
+	"github.com/totherme/unstructured/jsonschema"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func mustParseYAML(rawyaml string) unstructured.Data {
+	data, err := unstructured.ParseYAML(rawyaml)
+	Expect(err).NotTo(HaveOccurred())
+	return data
+}
+
+func violationsOf(schema string, doc string) []jsonschema.Violation {
+	violations, err := jsonschema.Validate(mustParseYAML(schema), mustParseYAML(doc))
+	Expect(err).NotTo(HaveOccurred())
+	return violations
+}
+
+func violation(instancePointer, schemaPointer, message string) jsonschema.Violation {
+	return jsonschema.Violation{
+		InstancePointer: instancePointer,
+		SchemaPointer:   schemaPointer,
+		Message:         message,
+	}
+}
+
+var _ = Describe("Validate", func() {
+	Context("with a realistic schema", func() {
+		var schema string
+
+		BeforeEach(func() {
+			schema = `
+$schema: https://json-schema.org/draft/2020-12/schema
+type: object
+required: [name, instance_groups]
+properties:
+  name: {type: string, minLength: 1}
+  instance_groups:
+    type: array
+    items: {$ref: "#/$defs/instance_group"}
+$defs:
+  instance_group:
+    type: object
+    required: [name, instances]
+    additionalProperties: false
+    properties:
+      name: {type: string, pattern: "^[a-z_]+$"}
+      instances: {type: integer, minimum: 0}
+      azs: {type: array, items: {enum: [z1, z2, z3]}, uniqueItems: true}
+`
+		})
+
+		It("accepts conforming documents", func() {
+			Expect(violationsOf(schema, `
+name: my-deployment
+instance_groups:
+- {name: web, instances: 2, azs: [z1, z2]}
+- {name: db, instances: 1}
+`)).To(BeEmpty())
+		})
+
+		It("reports every violation with instance and schema pointers", func() {
+			Expect(violationsOf(schema, `
+name: ""
+instance_groups:
+- {name: Web, instances: 2.5, azs: [z1, z4, z1]}
+- {instances: -1, size: large}
+`)).To(ConsistOf(
+				violation("/name", "/properties/name/minLength", "is 0 characters long, which is fewer than 1"),
+				violation("/instance_groups/0/name", "/$defs/instance_group/properties/name/pattern", `"Web" doesn't match the pattern '^[a-z_]+$'`),
+				violation("/instance_groups/0/instances", "/$defs/instance_group/properties/instances/type", "expected integer, got number"),
+				violation("/instance_groups/0/azs", "/$defs/instance_group/properties/azs/uniqueItems", "items 0 and 2 are the same"),
+				violation("/instance_groups/0/azs/1", "/$defs/instance_group/properties/azs/items/enum", `"z4" is not one of the allowed values`),
+				violation("/instance_groups/1", "/$defs/instance_group/required", "is missing the required property 'name'"),
+				violation("/instance_groups/1/instances", "/$defs/instance_group/properties/instances/minimum", "-1 is less than the minimum 0"),
+				violation("/instance_groups/1/size", "/$defs/instance_group/additionalProperties", "no value is allowed here"),
+			))
+		})
+	})
+
+	DescribeTable("individual keywords",
+		func(schema string, doc string, expected []jsonschema.Violation) {
+			violations := violationsOf(schema, doc)
+			if len(expected) == 0 {
+				Expect(violations).To(BeEmpty())
+			} else {
+				Expect(violations).To(ConsistOf(expected))
+			}
+		},
+		Entry("type, passing", `{type: [string, "null"]}`, `null`, nil),
+		Entry("type, failing", `{type: [string, "null"]}`, `3`,
+			[]jsonschema.Violation{violation("", "/type", "expected string or null, got number")}),
+		Entry("integer accepts whole numbers", `{type: integer}`, `3.0`, nil),
+		Entry("const", `{const: {a: 1}}`, `{a: 2}`,
+			[]jsonschema.Violation{violation("", "/const", "an object is not the required constant value")}),
+		Entry("multipleOf", `{multipleOf: 0.1}`, `0.3`, nil),
+		Entry("multipleOf, failing", `{multipleOf: 2}`, `3`,
+			[]jsonschema.Violation{violation("", "/multipleOf", "3 is not a multiple of 2")}),
+		Entry("exclusive bounds", `{exclusiveMinimum: 0, exclusiveMaximum: 10}`, `10`,
+			[]jsonschema.Violation{violation("", "/exclusiveMaximum", "10 is not less than 10")}),
+		Entry("maxLength counts characters", `{maxLength: 2}`, `"çà"`, nil),
+		Entry("maxItems and minItems", `{maxItems: 1, minItems: 1}`, `[]`,
+			[]jsonschema.Violation{violation("", "/minItems", "has 0 items, which is fewer than 1")}),
+		Entry("prefixItems and items", `{prefixItems: [{type: string}], items: {type: number}}`, `[1, 2, "three"]`,
+			[]jsonschema.Violation{
+				violation("/0", "/prefixItems/0/type", "expected string, got number"),
+				violation("/2", "/items/type", "expected number, got string"),
+			}),
+		Entry("contains", `{contains: {type: string}, minContains: 2, maxContains: 3}`, `[a, 1, b]`, nil),
+		Entry("contains, failing", `{contains: {type: string}, minContains: 2}`, `[a, 1]`,
+			[]jsonschema.Violation{violation("", "/contains", "has 1 items matching the contains schema, which is fewer than 2")}),
+		Entry("maxProperties and minProperties", `{maxProperties: 1}`, `{a: 1, b: 2}`,
+			[]jsonschema.Violation{violation("", "/maxProperties", "has 2 properties, which is more than 1")}),
+		Entry("patternProperties", `{patternProperties: {"^x-": {type: string}}, additionalProperties: {type: number}}`, `{x-a: 1, b: 2}`,
+			[]jsonschema.Violation{violation("/x-a", "/patternProperties/^x-/type", "expected string, got number")}),
+		Entry("propertyNames", `{propertyNames: {maxLength: 3}}`, `{abcd: 1}`,
+			[]jsonschema.Violation{violation("/abcd", "/propertyNames/maxLength", "is 4 characters long, which is more than 3")}),
+		Entry("dependentRequired", `{dependentRequired: {tls: [cert]}}`, `{tls: true}`,
+			[]jsonschema.Violation{violation("", "/dependentRequired", "has the property 'tls', so it must also have 'cert'")}),
+		Entry("dependentSchemas", `{dependentSchemas: {tls: {required: [cert]}}}`, `{tls: true}`,
+			[]jsonschema.Violation{violation("", "/dependentSchemas/tls/required", "is missing the required property 'cert'")}),
+		Entry("allOf", `{allOf: [{type: string}, {minLength: 3}]}`, `ab`,
+			[]jsonschema.Violation{violation("", "/allOf/1/minLength", "is 2 characters long, which is fewer than 3")}),
+		Entry("anyOf", `{anyOf: [{type: string}, {type: number}]}`, `true`,
+			[]jsonschema.Violation{violation("", "/anyOf", "doesn't match any of the allowed schemas")}),
+		Entry("oneOf", `{oneOf: [{type: number}, {minimum: 2}]}`, `3`,
+			[]jsonschema.Violation{violation("", "/oneOf", "matches more than one of the allowed schemas (0, 1)")}),
+		Entry("not", `{not: {type: string}}`, `a`,
+			[]jsonschema.Violation{violation("", "/not", "matches a schema which it must not match")}),
+		Entry("if/then/else", `{if: {type: string}, then: {minLength: 2}, else: {minimum: 5}}`, `4`,
+			[]jsonschema.Violation{violation("", "/else/minimum", "4 is less than the minimum 5")}),
+		Entry("boolean schemas", `{properties: {a: true, b: false}}`, `{a: 1, b: 2}`,
+			[]jsonschema.Violation{violation("/b", "/properties/b", "no value is allowed here")}),
+		Entry("format is only an annotation", `{format: email}`, `"not an email"`, nil),
+		Entry("unevaluatedProperties sees through allOf", `
+allOf: [{properties: {a: true}}]
+properties: {b: true}
+unevaluatedProperties: false
+`, `{a: 1, b: 2, c: 3}`,
+			[]jsonschema.Violation{violation("/c", "/unevaluatedProperties", "no value is allowed here")}),
+		Entry("unevaluatedProperties ignores failed anyOf branches", `
+anyOf: [{properties: {a: {type: string}}}, {properties: {b: true}}]
+unevaluatedProperties: false
+`, `{a: 1, b: 2}`,
+			[]jsonschema.Violation{violation("/a", "/unevaluatedProperties", "no value is allowed here")}),
+		Entry("unevaluatedItems", `{prefixItems: [true], unevaluatedItems: {type: string}}`, `[1, a, 2]`,
+			[]jsonschema.Violation{violation("/2", "/unevaluatedItems/type", "expected string, got number")}),
+	)
+
+	Describe("references", func() {
+		It("follows references to anchors", func() {
+			Expect(violationsOf(`
+properties: {port: {$ref: "#port"}}
+$defs:
+  port: {$anchor: port, type: integer, maximum: 65535}
+`, `{port: 70000}`)).To(ConsistOf(violation("/port", "/$defs/port/maximum", "70000 is greater than the maximum 65535")))
+		})
+
+		It("follows references to subschema $ids", func() {
+			Expect(violationsOf(`
+$id: https://example.com/root.json
+properties: {port: {$ref: "port.json"}}
+$defs:
+  port: {$id: port.json, type: integer}
+`, `{port: "80"}`)).To(ConsistOf(violation("/port", "/$defs/port/type", "expected integer, got string")))
+		})
+
+		It("follows recursive references", func() {
+			schema := `
+$defs:
+  tree: {type: object, properties: {children: {type: array, items: {$ref: "#/$defs/tree"}}}, required: [name]}
+$ref: "#/$defs/tree"
+`
+			Expect(violationsOf(schema, `{name: a, children: [{name: b, children: [{}]}]}`)).
+				To(ConsistOf(violation("/children/0/children/0", "/$defs/tree/required", "is missing the required property 'name'")))
+		})
+
+		It("applies the siblings of $ref", func() {
+			Expect(violationsOf(`{$ref: "#/$defs/s", maxLength: 1, $defs: {s: {type: string}}}`, `ab`)).
+				To(ConsistOf(violation("", "/maxLength", "is 2 characters long, which is more than 1")))
+		})
+	})
+
+	Describe("draft-07 compatibility", func() {
+		It("reads items, additionalItems, definitions and dependencies the draft-07 way", func() {
+			Expect(violationsOf(`
+$schema: http://json-schema.org/draft-07/schema#
+properties:
+  pair: {items: [{type: string}, {type: number}], additionalItems: false}
+  all: {items: {type: string}}
+  port: {$ref: "#/definitions/port"}
+dependencies:
+  tls: [cert]
+  debug: {required: [log_level]}
+definitions:
+  port: {type: integer}
+`, `{pair: [a, 1, extra], all: [a, 1], port: 8.5, tls: true, debug: true}`)).To(ConsistOf(
+				violation("/pair/2", "/properties/pair/additionalItems", "no value is allowed here"),
+				violation("/all/1", "/properties/all/items/type", "expected string, got number"),
+				violation("/port", "/definitions/port/type", "expected integer, got number"),
+				violation("", "/dependencies", "has the property 'tls', so it must also have 'cert'"),
+				violation("", "/dependencies/debug/required", "is missing the required property 'log_level'"),
+			))
+		})
+
+		It("reads a list of items the draft-07 way, even without $schema", func() {
+			Expect(violationsOf(`{type: array, items: [{type: string}]}`, `[1, 2]`)).
+				To(ConsistOf(violation("/0", "/items/0/type", "expected string, got number")))
+			Expect(violationsOf(`{items: [{type: string}], additionalItems: false}`, `[a, b]`)).
+				To(ConsistOf(violation("/1", "/additionalItems", "no value is allowed here")))
+		})
+
+		It("ignores the siblings of $ref", func() {
+			Expect(violationsOf(`
+$schema: http://json-schema.org/draft-07/schema#
+$ref: "#/definitions/s"
+maxLength: 1
+definitions: {s: {type: string}}
+`, `ab`)).To(BeEmpty())
+		})
+	})
+
+	Describe("Violation", func() {
+		It("is an error describing where the problem is", func() {
+			Expect(violation("/a", "/properties/a/type", "expected string, got number").Error()).
+				To(Equal("at '/a': expected string, got number (schema '/properties/a/type')"))
+		})
+	})
+
+	Context("when the schema is invalid", func() {
+		DescribeTable("Compile returns a helpful error", func(schema string, message string) {
+			_, err := jsonschema.Compile(mustParseYAML(schema))
+			Expect(err).To(MatchError(ContainSubstring(message)))
+		},
+			Entry("a schema which isn't an object", `{properties: {a: 3}}`, "Invalid schema at '/properties/a'"),
+			Entry("a remote reference", `{$ref: "https://example.com/schema.json"}`, "remote references aren't supported"),
+			Entry("a missing anchor", `{$ref: "#nowhere"}`, "there's no anchor called 'nowhere'"),
+			Entry("a missing pointer", `{$ref: "#/$defs/nothing"}`, "there's no schema at '/$defs/nothing'"),
+			Entry("a bad pattern", `{pattern: "("}`, "Invalid pattern '('"),
+			Entry("a reference to itself", `{$ref: "#/$defs/x", $defs: {x: {$ref: "#/$defs/x"}}}`,
+				"Invalid schema at '/$defs/x': its $ref leads back to it without descending into the document"),
+			Entry("a reference cycle through a combinator", `{$defs: {a: {allOf: [{$ref: "#/$defs/b"}]}, b: {not: {$ref: "#/$defs/a"}}}, $ref: "#/$defs/a"}`,
+				"its $ref leads back to it without descending into the document"),
+		)
+	})
+})
@@ -0,0 +1,209 @@
+package unstructured
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// FindAll finds every element in a list which matches the provided matcher.
+// If this Data struct does not represent a list, there are no matches.
+func (j Data) FindAll(match ElementMatcher) []Data {
+	matches := []Data{}
+	if !j.IsList() {
+		return matches
+	}
+	for _, elem := range j.UnsafeListValue() {
+		if match(elem) {
+			matches = append(matches, elem)
+		}
+	}
+	return matches
+}
+
+// FindIndex finds the index of the first element in a list which matches the
+// provided matcher. Like FindElem, it uses the 'comma ok' idiom to report
+// whether there was such an element.
+func (j Data) FindIndex(match ElementMatcher) (int, bool) {
+	if !j.IsList() {
+		return 0, false
+	}
+	for i, elem := range j.UnsafeListValue() {
+		if match(elem) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// Filter returns a new list containing only the elements of this list which
+// match the provided matcher, in their original order. The elements
+// themselves are shared with this list, not copied. If this Data struct does
+// not represent a list, the result is an empty list.
+func (j Data) Filter(match ElementMatcher) Data {
+	filtered := []interface{}{}
+	for _, elem := range j.FindAll(match) {
+		filtered = append(filtered, elem.data)
+	}
+	return Data{data: filtered}
+}
+
+// Map returns a new list made by calling `f` on each element of this list.
+// If `f` returns an error, Map stops and returns that error, along with the
+// index of the element which caused it. If this Data struct does not
+// represent a list, Map returns an error.
+func (j Data) Map(f func(Data) (interface{}, error)) (Data, error) {
+	if !j.IsList() {
+		return Data{}, fmt.Errorf("This is not a list, so you can't Map over it")
+	}
+	mapped := []interface{}{}
+	for i, elem := range j.UnsafeListValue() {
+		val, err := f(elem)
+		if err != nil {
+			return Data{}, fmt.Errorf("Map failed on element %d: %s", i, err)
+		}
+		mapped = append(mapped, val)
+	}
+	return Data{data: mapped}, nil
+}
+
+// SortBy returns a new list containing the elements of this list, sorted by
+// the values at pointer `p` within each element. Strings sort alphabetically
+// and numbers numerically. Elements without a value at `p` sort first,
+// followed by nulls, bools, numbers, strings, lists and objects. The sort is
+// stable, so elements with equal values keep their original order.
+//
+// If this Data struct does not represent a list, or `p` is not a valid
+// pointer, SortBy returns an error.
+func (j Data) SortBy(p string) (Data, error) {
+	keys, err := j.listKeys(p, "sort")
+	if err != nil {
+		return Data{}, err
+	}
+	list := j.data.([]interface{})
+	order := make([]int, len(list))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return compareSortKeys(keys[order[a]], keys[order[b]]) < 0
+	})
+	sorted := make([]interface{}, len(list))
+	for i, index := range order {
+		sorted[i] = list[index]
+	}
+	return Data{data: sorted}, nil
+}
+
+// UniqueBy returns a new list containing the elements of this list, leaving
+// out any element whose value at pointer `p` is the same as an earlier
+// element's. Elements without a value at `p` are all considered the same as
+// each other.
+//
+// If this Data struct does not represent a list, or `p` is not a valid
+// pointer, UniqueBy returns an error.
+func (j Data) UniqueBy(p string) (Data, error) {
+	keys, err := j.listKeys(p, "deduplicate")
+	if err != nil {
+		return Data{}, err
+	}
+	list := j.data.([]interface{})
+	unique := []interface{}{}
+	var seen []sortKey
+	for i, key := range keys {
+		duplicate := false
+		for _, seenKey := range seen {
+			if seenKey.found == key.found && reflect.DeepEqual(seenKey.val, key.val) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			seen = append(seen, key)
+			unique = append(unique, list[i])
+		}
+	}
+	return Data{data: unique}, nil
+}
+
+type sortKey struct {
+	val   interface{}
+	found bool
+}
+
+// listKeys finds the value at pointer `p` within each element of this list.
+func (j Data) listKeys(p string, verb string) ([]sortKey, error) {
+	if !j.IsList() {
+		return nil, fmt.Errorf("This is not a list, so you can't %s it", verb)
+	}
+	tokens, err := SplitPointer(p)
+	if err != nil {
+		return nil, err
+	}
+	list := j.data.([]interface{})
+	keys := make([]sortKey, len(list))
+	for i, elem := range list {
+		val, found, err := getByTokens(elem, tokens)
+		keys[i] = sortKey{val: val, found: found && err == nil}
+	}
+	return keys, nil
+}
+
+func compareSortKeys(a, b sortKey) int {
+	if rankA, rankB := sortRank(a), sortRank(b); rankA != rankB {
+		return rankA - rankB
+	}
+	switch av := a.val.(type) {
+	case bool:
+		bv := b.val.(bool)
+		if av == bv {
+			return 0
+		}
+		if !av {
+			return -1
+		}
+		return 1
+	case float64:
+		bv := b.val.(float64)
+		if av < bv {
+			return -1
+		}
+		if av > bv {
+			return 1
+		}
+		return 0
+	case string:
+		bv := b.val.(string)
+		if av < bv {
+			return -1
+		}
+		if av > bv {
+			return 1
+		}
+		return 0
+	default:
+		return 0
+	}
+}
+
+func sortRank(key sortKey) int {
+	if !key.found {
+		return 0
+	}
+	switch key.val.(type) {
+	case nil:
+		return 1
+	case bool:
+		return 2
+	case float64:
+		return 3
+	case string:
+		return 4
+	case []interface{}:
+		return 5
+	case map[string]interface{}:
+		return 6
+	default:
+		return 7
+	}
+}
@@ -0,0 +1,174 @@
+package unstructured_test
+
+import (
+	"fmt"
+
+	"github.com/totherme/unstructured"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("List operations", func() {
+	var (
+		groups    unstructured.Data
+		notAList  unstructured.Data
+		isBackend unstructured.ElementMatcher
+	)
+
+	names := func(list unstructured.Data) []string {
+		var result []string
+		for _, elem := range list.UnsafeListValue() {
+			result = append(result, elem.F("name").UnsafeStringValue())
+		}
+		return result
+	}
+
+	BeforeEach(func() {
+		groups = mustParseYAML(`
+- {name: web, type: frontend, instances: 3}
+- {name: db, type: backend, instances: 1}
+- {name: worker, type: backend, instances: 10}
+- {name: cache, type: backend}
+- {name: db-replica, type: backend, instances: 1}
+`)
+		notAList = mustParseYAML(`{name: web}`)
+		isBackend = func(d unstructured.Data) bool {
+			return d.F("type").UnsafeStringValue() == "backend"
+		}
+	})
+
+	Describe("FindAll", func() {
+		It("finds every matching element", func() {
+			found := groups.FindAll(isBackend)
+			Expect(found).To(HaveLen(4))
+			Expect(found[0].F("name").UnsafeStringValue()).To(Equal("db"))
+			Expect(found[3].F("name").UnsafeStringValue()).To(Equal("db-replica"))
+		})
+
+		It("finds nothing in things which aren't lists", func() {
+			Expect(notAList.FindAll(isBackend)).To(BeEmpty())
+		})
+	})
+
+	Describe("FindIndex", func() {
+		It("finds the index of the first matching element", func() {
+			index, ok := groups.FindIndex(isBackend)
+			Expect(ok).To(BeTrue())
+			Expect(index).To(Equal(1))
+		})
+
+		It("uses the 'comma ok' idiom to tell us when nothing matches", func() {
+			_, ok := groups.FindIndex(func(unstructured.Data) bool { return false })
+			Expect(ok).To(BeFalse())
+			_, ok = notAList.FindIndex(isBackend)
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Describe("Filter", func() {
+		It("returns a list of the matching elements", func() {
+			Expect(names(groups.Filter(isBackend))).To(Equal([]string{"db", "worker", "cache", "db-replica"}))
+		})
+
+		It("leaves the original list alone", func() {
+			groups.Filter(isBackend)
+			Expect(groups.UnsafeListValue()).To(HaveLen(5))
+		})
+
+		It("returns an empty list for things which aren't lists", func() {
+			filtered := notAList.Filter(isBackend)
+			Expect(filtered.IsList()).To(BeTrue())
+			Expect(filtered.UnsafeListValue()).To(BeEmpty())
+		})
+	})
+
+	Describe("Map", func() {
+		It("returns a list of the results", func() {
+			mapped, err := groups.Map(func(d unstructured.Data) (interface{}, error) {
+				return d.F("name").UnsafeStringValue() + "-group", nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mapped).To(Equal(mustParseYAML("[web-group, db-group, worker-group, cache-group, db-replica-group]")))
+		})
+
+		It("stops at the first error", func() {
+			calls := 0
+			_, err := groups.Map(func(d unstructured.Data) (interface{}, error) {
+				calls++
+				return d.GetByPointer("/instances")
+			})
+			Expect(err).To(MatchError(ContainSubstring("Map failed on element 3: Object has no key 'instances'")))
+			Expect(calls).To(Equal(4))
+		})
+
+		It("fails for things which aren't lists", func() {
+			_, err := notAList.Map(func(d unstructured.Data) (interface{}, error) {
+				return nil, fmt.Errorf("this should never be called")
+			})
+			Expect(err).To(MatchError(ContainSubstring("not a list")))
+		})
+	})
+
+	Describe("SortBy", func() {
+		It("sorts by strings", func() {
+			sorted, err := groups.SortBy("/name")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names(sorted)).To(Equal([]string{"cache", "db", "db-replica", "web", "worker"}))
+		})
+
+		It("sorts numbers numerically, with missing values first, keeping equal elements in order", func() {
+			sorted, err := groups.SortBy("/instances")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names(sorted)).To(Equal([]string{"cache", "db", "db-replica", "web", "worker"}))
+		})
+
+		It("orders values of different types by type", func() {
+			mixed := mustParseYAML(`[{v: a}, {v: 2}, {v: [1]}, {v: null}, {v: true}, {v: {}}, {v: false}, {}]`)
+			sorted, err := mixed.SortBy("/v")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sorted).To(Equal(mustParseYAML(`[{}, {v: null}, {v: false}, {v: true}, {v: 2}, {v: a}, {v: [1]}, {v: {}}]`)))
+		})
+
+		It("leaves the original list alone", func() {
+			_, err := groups.SortBy("/name")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names(groups)).To(Equal([]string{"web", "db", "worker", "cache", "db-replica"}))
+		})
+
+		It("fails for things which aren't lists", func() {
+			_, err := notAList.SortBy("/name")
+			Expect(err).To(MatchError(ContainSubstring("not a list")))
+		})
+
+		It("fails for invalid pointers", func() {
+			_, err := groups.SortBy("name")
+			Expect(err).To(MatchError(ContainSubstring("JSON pointer must be empty or start with a \"/\"")))
+		})
+	})
+
+	Describe("UniqueBy", func() {
+		It("keeps the first element with each value", func() {
+			unique, err := groups.UniqueBy("/type")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names(unique)).To(Equal([]string{"web", "db"}))
+		})
+
+		It("treats all the elements without a value as the same", func() {
+			unique, err := groups.UniqueBy("/instances")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names(unique)).To(Equal([]string{"web", "db", "worker", "cache"}))
+		})
+
+		It("compares structured values", func() {
+			unique, err := mustParseYAML(`[{v: [1, 2]}, {v: [1, 2]}, {v: [2, 1]}]`).UniqueBy("/v")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(unique).To(Equal(mustParseYAML(`[{v: [1, 2]}, {v: [2, 1]}]`)))
+		})
+
+		It("fails for things which aren't lists", func() {
+			_, err := notAList.UniqueBy("/name")
+			Expect(err).To(MatchError(ContainSubstring("not a list")))
+		})
+	})
+})
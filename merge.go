@@ -0,0 +1,286 @@
+package unstructured
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// A ListStrategy says how Merge combines a list in the base with a list in
+// the overlay.
+type ListStrategy int
+
+const (
+	// ListReplace uses the overlay's list, ignoring the base's. This is the
+	// default.
+	ListReplace ListStrategy = iota
+	// ListAppend uses the base's elements followed by the overlay's.
+	ListAppend
+	// ListMergeByKey merges object elements which have the same value for
+	// some key field. It is set up with MergeListsByKeyAt.
+	ListMergeByKey
+)
+
+// A ConflictStrategy says what Merge does when the base and the overlay have
+// different values at the same pointer, and those values can't be merged --
+// for example two different strings, or a number and an object.
+type ConflictStrategy int
+
+const (
+	// OverlayWins uses the overlay's value. This is the default.
+	OverlayWins ConflictStrategy = iota
+	// BaseWins uses the base's value.
+	BaseWins
+	// ConflictError makes Merge return an error.
+	ConflictError
+)
+
+// A MergeOption changes the behaviour of Merge. Options which apply to
+// particular pointers take a pointer pattern, which may use the wildcards
+// described in GlobPointer. If several options of the same kind match a
+// pointer, the last one wins.
+type MergeOption func(*mergeConfig)
+
+type mergeConfig struct {
+	lists       []listRule
+	conflicts   []conflictRule
+	nullDeletes bool
+	patternErr  error
+}
+
+type listRule struct {
+	pattern  []string
+	strategy ListStrategy
+	key      string
+}
+
+type conflictRule struct {
+	pattern  []string
+	strategy ConflictStrategy
+}
+
+// ListStrategyAt sets the ListStrategy for lists at pointers matching
+// `pattern`. To merge lists by key, use MergeListsByKeyAt instead.
+func ListStrategyAt(pattern string, strategy ListStrategy) MergeOption {
+	return func(c *mergeConfig) {
+		c.lists = append(c.lists, listRule{pattern: c.parsePattern(pattern), strategy: strategy})
+	}
+}
+
+// MergeListsByKeyAt makes Merge combine lists at pointers matching `pattern`
+// element by element. Each object in the overlay's list is merged into the
+// object in the base's list with the same value for the field `key`, or
+// appended if there is no such object. For example,
+// `MergeListsByKeyAt("/instance_groups", "name")` merges instance groups by
+// name.
+func MergeListsByKeyAt(pattern string, key string) MergeOption {
+	return func(c *mergeConfig) {
+		c.lists = append(c.lists, listRule{pattern: c.parsePattern(pattern), strategy: ListMergeByKey, key: key})
+	}
+}
+
+// ConflictStrategyAt sets the ConflictStrategy for values at pointers
+// matching `pattern`.
+func ConflictStrategyAt(pattern string, strategy ConflictStrategy) MergeOption {
+	return func(c *mergeConfig) {
+		c.conflicts = append(c.conflicts, conflictRule{pattern: c.parsePattern(pattern), strategy: strategy})
+	}
+}
+
+// NullMeansDelete makes a null field in the overlay remove that field from
+// the result, rather than setting it to null.
+func NullMeansDelete() MergeOption {
+	return func(c *mergeConfig) {
+		c.nullDeletes = true
+	}
+}
+
+func (c *mergeConfig) parsePattern(pattern string) []string {
+	tokens, err := SplitPointer(pattern)
+	if err != nil && c.patternErr == nil {
+		c.patternErr = fmt.Errorf("Invalid pattern '%s': %s", pattern, err)
+	}
+	return tokens
+}
+
+func (c *mergeConfig) listRuleAt(path []string) listRule {
+	for i := len(c.lists) - 1; i >= 0; i-- {
+		if matchTokens(c.lists[i].pattern, path) {
+			return c.lists[i]
+		}
+	}
+	return listRule{strategy: ListReplace}
+}
+
+func (c *mergeConfig) conflictStrategyAt(path []string) ConflictStrategy {
+	for i := len(c.conflicts) - 1; i >= 0; i-- {
+		if matchTokens(c.conflicts[i].pattern, path) {
+			return c.conflicts[i].strategy
+		}
+	}
+	return OverlayWins
+}
+
+// Merge deeply merges `overlay` on top of `base`, and returns the result.
+// Neither `base` nor `overlay` is changed.
+//
+// Objects are merged key by key. By default, the overlay's lists replace the
+// base's, and where the two have different values which can't be merged,
+// the overlay's value wins. Both of these can be changed for particular
+// pointers with MergeOptions.
+func Merge(base, overlay Data, opts ...MergeOption) (Data, error) {
+	config := &mergeConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+	if config.patternErr != nil {
+		return Data{}, config.patternErr
+	}
+	merged, err := config.merge(base.data, overlay.data, nil)
+	if err != nil {
+		return Data{}, err
+	}
+	return Data{data: merged}, nil
+}
+
+func (c *mergeConfig) merge(base, overlay interface{}, path []string) (interface{}, error) {
+	switch o := overlay.(type) {
+	case map[string]interface{}:
+		if b, ok := base.(map[string]interface{}); ok {
+			return c.mergeObjects(b, o, path)
+		}
+	case []interface{}:
+		if b, ok := base.([]interface{}); ok {
+			return c.mergeLists(b, o, path)
+		}
+	}
+
+	if reflect.DeepEqual(base, overlay) {
+		return deepCopy(base), nil
+	}
+	switch c.conflictStrategyAt(path) {
+	case BaseWins:
+		return deepCopy(base), nil
+	case ConflictError:
+		return nil, fmt.Errorf("Merge conflict at pointer '%s': the base has %s and the overlay has %s",
+			JoinPointer(path), describeValue(base), describeValue(overlay))
+	default:
+		return c.copyOverlay(overlay), nil
+	}
+}
+
+func (c *mergeConfig) mergeObjects(base, overlay map[string]interface{}, path []string) (interface{}, error) {
+	merged := deepCopy(base).(map[string]interface{})
+	for _, key := range sortedKeys(overlay) {
+		val := overlay[key]
+		if val == nil && c.nullDeletes {
+			delete(merged, key)
+			continue
+		}
+		if baseVal, ok := base[key]; ok {
+			mergedVal, err := c.merge(baseVal, val, appendToken(path, key))
+			if err != nil {
+				return nil, err
+			}
+			merged[key] = mergedVal
+		} else {
+			merged[key] = c.copyOverlay(val)
+		}
+	}
+	return merged, nil
+}
+
+func (c *mergeConfig) mergeLists(base, overlay []interface{}, path []string) (interface{}, error) {
+	rule := c.listRuleAt(path)
+	switch rule.strategy {
+	case ListAppend:
+		merged := deepCopy(base).([]interface{})
+		for _, elem := range overlay {
+			merged = append(merged, c.copyOverlay(elem))
+		}
+		return merged, nil
+	case ListMergeByKey:
+		merged := deepCopy(base).([]interface{})
+		for _, elem := range overlay {
+			index, ok := indexByKey(merged, elem, rule.key)
+			if !ok {
+				merged = append(merged, c.copyOverlay(elem))
+				continue
+			}
+			mergedElem, err := c.merge(merged[index], elem, appendToken(path, strconv.Itoa(index)))
+			if err != nil {
+				return nil, err
+			}
+			merged[index] = mergedElem
+		}
+		return merged, nil
+	default:
+		return c.copyOverlay(overlay), nil
+	}
+}
+
+// indexByKey finds the index of the object in `list` which has the same value
+// for the field `key` as `elem`.
+func indexByKey(list []interface{}, elem interface{}, key string) (int, bool) {
+	ob, ok := elem.(map[string]interface{})
+	if !ok {
+		return 0, false
+	}
+	want, ok := ob[key]
+	if !ok {
+		return 0, false
+	}
+	for i, candidate := range list {
+		candidateOb, ok := candidate.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if got, ok := candidateOb[key]; ok && reflect.DeepEqual(got, want) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// copyOverlay copies a value from the overlay into the result. If nulls mean
+// delete, any null fields are left out, since there's nothing for them to
+// delete.
+func (c *mergeConfig) copyOverlay(val interface{}) interface{} {
+	if !c.nullDeletes {
+		return deepCopy(val)
+	}
+	switch v := val.(type) {
+	case map[string]interface{}:
+		ob := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			if child != nil {
+				ob[key] = c.copyOverlay(child)
+			}
+		}
+		return ob
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, child := range v {
+			list[i] = c.copyOverlay(child)
+		}
+		return list
+	default:
+		return v
+	}
+}
+
+// describeValue gives a short description of a raw value for error messages.
+func describeValue(val interface{}) string {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "a list"
+	case string:
+		return strconv.Quote(v)
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
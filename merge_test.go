@@ -0,0 +1,174 @@
+package unstructured_test
+
+import (
+	"github.com/totherme/unstructured"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Merge", func() {
+	var base unstructured.Data
+
+	BeforeEach(func() {
+		base = mustParseYAML(`
+name: my-deployment
+update: {canaries: 1, max_in_flight: 1}
+tags: [base]
+instance_groups:
+- name: web
+  instances: 2
+  azs: [z1]
+- name: db
+  instances: 1
+`)
+	})
+
+	It("merges objects key by key, letting the overlay win", func() {
+		merged, err := unstructured.Merge(base, mustParseYAML(`
+name: production
+update: {max_in_flight: 5, serial: false}
+`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(merged.F("name")).To(Equal(mustParseYAML("production")))
+		Expect(merged.F("update")).To(Equal(mustParseYAML("{canaries: 1, max_in_flight: 5, serial: false}")))
+		Expect(merged.F("tags")).To(Equal(mustParseYAML("[base]")))
+	})
+
+	It("replaces lists by default", func() {
+		merged, err := unstructured.Merge(base, mustParseYAML(`{tags: [overlay]}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(merged.F("tags")).To(Equal(mustParseYAML("[overlay]")))
+	})
+
+	It("can append lists", func() {
+		merged, err := unstructured.Merge(base, mustParseYAML(`{tags: [overlay]}`),
+			unstructured.ListStrategyAt("/tags", unstructured.ListAppend))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(merged.F("tags")).To(Equal(mustParseYAML("[base, overlay]")))
+	})
+
+	It("can merge lists by key", func() {
+		merged, err := unstructured.Merge(base, mustParseYAML(`
+instance_groups:
+- name: db
+  instances: 3
+- name: worker
+  instances: 5
+- name: web
+  azs: [z2]
+`), unstructured.MergeListsByKeyAt("/instance_groups", "name"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(merged.F("instance_groups")).To(Equal(mustParseYAML(`
+- name: web
+  instances: 2
+  azs: [z2]
+- name: db
+  instances: 3
+- name: worker
+  instances: 5
+`)))
+	})
+
+	It("matches pointer patterns against the merged document", func() {
+		merged, err := unstructured.Merge(base, mustParseYAML(`
+instance_groups:
+- name: web
+  azs: [z2]
+`),
+			unstructured.MergeListsByKeyAt("/instance_groups", "name"),
+			unstructured.ListStrategyAt("/instance_groups/*/azs", unstructured.ListAppend))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(merged.GetByPointer("/instance_groups/name=web/azs")).To(Equal(mustParseYAML("[z1, z2]")))
+	})
+
+	It("lets later options override earlier ones", func() {
+		merged, err := unstructured.Merge(base, mustParseYAML(`{tags: [overlay]}`),
+			unstructured.ListStrategyAt("/**", unstructured.ListAppend),
+			unstructured.ListStrategyAt("/tags", unstructured.ListReplace))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(merged.F("tags")).To(Equal(mustParseYAML("[overlay]")))
+	})
+
+	Describe("conflicts", func() {
+		var overlay unstructured.Data
+
+		BeforeEach(func() {
+			overlay = mustParseYAML(`
+name: production
+update: 7
+`)
+		})
+
+		It("lets the overlay win by default", func() {
+			merged, err := unstructured.Merge(base, overlay)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(merged.F("name")).To(Equal(mustParseYAML("production")))
+			Expect(merged.F("update")).To(Equal(mustParseYAML("7")))
+		})
+
+		It("can let the base win", func() {
+			merged, err := unstructured.Merge(base, overlay, unstructured.ConflictStrategyAt("/name", unstructured.BaseWins))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(merged.F("name")).To(Equal(mustParseYAML("my-deployment")))
+			Expect(merged.F("update")).To(Equal(mustParseYAML("7")))
+		})
+
+		It("can return an error", func() {
+			_, err := unstructured.Merge(base, overlay, unstructured.ConflictStrategyAt("/**", unstructured.ConflictError))
+			Expect(err).To(MatchError(`Merge conflict at pointer '/name': the base has "my-deployment" and the overlay has "production"`))
+
+			_, err = unstructured.Merge(base, overlay, unstructured.ConflictStrategyAt("/update", unstructured.ConflictError))
+			Expect(err).To(MatchError(`Merge conflict at pointer '/update': the base has an object and the overlay has 7`))
+		})
+
+		It("doesn't count equal values as conflicts", func() {
+			_, err := unstructured.Merge(base, mustParseYAML(`{name: my-deployment}`),
+				unstructured.ConflictStrategyAt("/**", unstructured.ConflictError))
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("nulls in the overlay", func() {
+		var overlay unstructured.Data
+
+		BeforeEach(func() {
+			overlay = mustParseYAML(`
+update: {canaries: null, serial: null}
+tags: null
+`)
+		})
+
+		It("set values to null by default", func() {
+			merged, err := unstructured.Merge(base, overlay)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(merged.F("tags").IsNull()).To(BeTrue())
+			Expect(merged.F("update")).To(Equal(mustParseYAML("{canaries: null, max_in_flight: 1, serial: null}")))
+		})
+
+		It("can delete values instead", func() {
+			merged, err := unstructured.Merge(base, overlay, unstructured.NullMeansDelete())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(merged.HasKey("tags")).To(BeFalse())
+			Expect(merged.F("update")).To(Equal(mustParseYAML("{max_in_flight: 1}")))
+		})
+	})
+
+	It("leaves the base and overlay alone", func() {
+		original := base.DeepCopy()
+		overlay := mustParseYAML(`{update: {serial: true}, tags: [overlay]}`)
+		merged, err := unstructured.Merge(base, overlay, unstructured.ListStrategyAt("/tags", unstructured.ListAppend))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(merged.F("update").SetField("canaries", 10)).To(Succeed())
+		Expect(merged.F("tags").SetElem(1, "changed")).To(Succeed())
+		Expect(base).To(Equal(original))
+		Expect(overlay).To(Equal(mustParseYAML(`{update: {serial: true}, tags: [overlay]}`)))
+	})
+
+	Context("when a pattern is invalid", func() {
+		It("returns an error", func() {
+			_, err := unstructured.Merge(base, base, unstructured.ListStrategyAt("tags", unstructured.ListAppend))
+			Expect(err).To(MatchError(ContainSubstring("Invalid pattern 'tags'")))
+		})
+	})
+})
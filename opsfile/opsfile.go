@@ -0,0 +1,133 @@
+// Package opsfile applies BOSH ops files to unstructured data.
+//
+// An ops file is a list of operations, each of which replaces or removes the
+// value at some path in a document:
+//
+//	---
+//	- type: replace
+//	  path: /instance_groups/name=web/instances
+//	  value: 3
+//	- type: remove
+//	  path: /instance_groups/name=web/jobs/name=debug?
+//
+// Paths are json pointers, with the extensions described in
+// unstructured.Data.GetByPointer: `name=web` selects a list element by the
+// value of one of its fields, a trailing `?` marks a segment as optional, and
+// a final `-` appends to a list.
+//
+// For more information on ops files, see https://bosh.io/docs/cli-ops-files/
+package opsfile
+
+import (
+	"fmt"
+
+	"github.com/totherme/unstructured"
+)
+
+const (
+	// OpReplace is the type of an operation which sets the value at its path,
+	// creating it if necessary.
+	OpReplace = "replace"
+	// OpRemove is the type of an operation which removes the value at its
+	// path.
+	OpRemove = "remove"
+)
+
+// Op is a single operation from an ops file.
+type Op struct {
+	Type  string
+	Path  string
+	Value unstructured.Data
+}
+
+// Parse reads the operations out of an ops file, which should be a list of
+// objects with `type` and `path` fields, and a `value` field for replace
+// operations.
+func Parse(ops unstructured.Data) ([]Op, error) {
+	list, err := ops.ListValue()
+	if err != nil {
+		return nil, fmt.Errorf("An ops file must be a list of operations")
+	}
+	parsed := []Op{}
+	for i, opData := range list {
+		op, err := parseOp(opData)
+		if err != nil {
+			return nil, fmt.Errorf("Operation [%d] is invalid: %s", i, err)
+		}
+		parsed = append(parsed, op)
+	}
+	return parsed, nil
+}
+
+func parseOp(opData unstructured.Data) (Op, error) {
+	if !opData.IsOb() {
+		return Op{}, fmt.Errorf("an operation must be an object")
+	}
+	op := Op{}
+	for _, field := range []struct {
+		key string
+		val *string
+	}{{"type", &op.Type}, {"path", &op.Path}} {
+		if !opData.HasKey(field.key) {
+			return Op{}, fmt.Errorf("missing '%s'", field.key)
+		}
+		val, err := opData.F(field.key).StringValue()
+		if err != nil {
+			return Op{}, fmt.Errorf("'%s' must be a string", field.key)
+		}
+		*field.val = val
+	}
+
+	switch op.Type {
+	case OpReplace:
+		if !opData.HasKey("value") {
+			return Op{}, fmt.Errorf("missing 'value'")
+		}
+		op.Value = opData.F("value")
+	case OpRemove:
+		if opData.HasKey("value") {
+			return Op{}, fmt.Errorf("a remove operation can't have a 'value'")
+		}
+	default:
+		return Op{}, fmt.Errorf("unknown type '%s'", op.Type)
+	}
+	return op, nil
+}
+
+// Apply applies the operations in the ops file `ops` to `doc`, in order, and
+// returns the resulting document. `doc` itself is left unchanged.
+//
+// If any operation fails, Apply returns an error saying which operation it
+// was, and the pointer at which it failed.
+func Apply(doc unstructured.Data, ops unstructured.Data) (unstructured.Data, error) {
+	parsed, err := Parse(ops)
+	if err != nil {
+		return unstructured.Data{}, err
+	}
+	return ApplyOps(doc, parsed)
+}
+
+// ApplyOps is like Apply, but takes operations which have already been
+// parsed.
+func ApplyOps(doc unstructured.Data, ops []Op) (unstructured.Data, error) {
+	result := doc.DeepCopy()
+	for i, op := range ops {
+		var err error
+		result, err = applyOp(result, op)
+		if err != nil {
+			return unstructured.Data{}, fmt.Errorf("Operation [%d] (%s '%s') failed: %s", i, op.Type, op.Path, err)
+		}
+	}
+	return result, nil
+}
+
+func applyOp(doc unstructured.Data, op Op) (unstructured.Data, error) {
+	switch op.Type {
+	case OpReplace:
+		return doc.WithPointerSet(op.Path, op.Value.DeepCopy().RawValue())
+	case OpRemove:
+		return doc.WithPointerDeleted(op.Path)
+	default:
+		return doc, fmt.Errorf("unknown type '%s'", op.Type)
+	}
+}
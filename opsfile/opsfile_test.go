@@ -0,0 +1,193 @@
+package opsfile_test
+
+import (
+	"github.com/totherme/unstructured"
+	"github.com/totherme/unstructured/opsfile"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func mustParseYAML(rawyaml string) unstructured.Data {
+	data, err := unstructured.ParseYAML(rawyaml)
+	Expect(err).NotTo(HaveOccurred())
+	return data
+}
+
+var _ = Describe("Apply", func() {
+	var manifest unstructured.Data
+
+	BeforeEach(func() {
+		manifest = mustParseYAML(`
+name: my-deployment
+instance_groups:
+- name: web
+  instances: 2
+  jobs:
+  - name: nginx
+  - name: debug
+- name: db
+  instances: 1
+  jobs:
+  - name: postgres
+`)
+	})
+
+	It("replaces values", func() {
+		result, err := opsfile.Apply(manifest, mustParseYAML(`
+- type: replace
+  path: /instance_groups/name=web/instances
+  value: 3
+`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.GetByPointer("/instance_groups/0/instances")).To(Equal(mustParseYAML("3")))
+	})
+
+	It("removes values", func() {
+		result, err := opsfile.Apply(manifest, mustParseYAML(`
+- type: remove
+  path: /instance_groups/name=web/jobs/name=debug
+`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.GetByPointer("/instance_groups/0/jobs")).To(Equal(mustParseYAML("[{name: nginx}]")))
+	})
+
+	It("appends to lists", func() {
+		result, err := opsfile.Apply(manifest, mustParseYAML(`
+- type: replace
+  path: /instance_groups/name=db/jobs/-
+  value:
+    name: backup
+`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.GetByPointer("/instance_groups/1/jobs")).To(Equal(mustParseYAML("[{name: postgres}, {name: backup}]")))
+	})
+
+	It("creates optional segments", func() {
+		result, err := opsfile.Apply(manifest, mustParseYAML(`
+- type: replace
+  path: /instance_groups/name=web/jobs/name=nginx/properties?/port
+  value: 8080
+- type: replace
+  path: /instance_groups/name=worker?/instances
+  value: 5
+`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.GetByPointer("/instance_groups/0/jobs/0/properties")).To(Equal(mustParseYAML("{port: 8080}")))
+		Expect(result.GetByPointer("/instance_groups/2")).To(Equal(mustParseYAML("{name: worker, instances: 5}")))
+	})
+
+	It("creates every segment after an optional one", func() {
+		result, err := opsfile.Apply(manifest, mustParseYAML(`
+- type: replace
+  path: /b?/c/d
+  value: 1
+`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.GetByPointer("/b")).To(Equal(mustParseYAML("{c: {d: 1}}")))
+	})
+
+	It("ignores removals of missing optional segments", func() {
+		result, err := opsfile.Apply(manifest, mustParseYAML(`
+- type: remove
+  path: /instance_groups/name=worker?
+`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(manifest))
+	})
+
+	It("applies operations in order", func() {
+		result, err := opsfile.Apply(manifest, mustParseYAML(`
+- type: replace
+  path: /instance_groups/name=web/name
+  value: frontend
+- type: replace
+  path: /instance_groups/name=frontend/instances
+  value: 4
+`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.GetByPointer("/instance_groups/0")).To(Equal(mustParseYAML(`
+name: frontend
+instances: 4
+jobs: [{name: nginx}, {name: debug}]
+`)))
+	})
+
+	It("can replace the whole document", func() {
+		result, err := opsfile.Apply(manifest, mustParseYAML(`
+- type: replace
+  path: ""
+  value: {name: something-else}
+`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(mustParseYAML("{name: something-else}")))
+	})
+
+	It("can add to and remove from a list at the root", func() {
+		result, err := opsfile.Apply(mustParseYAML(`[{name: a}, {name: b}]`), mustParseYAML(`
+- type: replace
+  path: /-
+  value: {name: c}
+- type: remove
+  path: /name=a
+`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(mustParseYAML("[{name: b}, {name: c}]")))
+	})
+
+	It("leaves the original document alone", func() {
+		original := manifest.DeepCopy()
+		_, err := opsfile.Apply(manifest, mustParseYAML(`
+- type: replace
+  path: /instance_groups/name=web/jobs/-
+  value: {name: haproxy}
+- type: remove
+  path: /name
+`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(manifest).To(Equal(original))
+	})
+
+	It("doesn't share values between the ops file and the result", func() {
+		ops := mustParseYAML(`
+- type: replace
+  path: /update
+  value: {canaries: 1}
+`)
+		result, err := opsfile.Apply(manifest, ops)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.SetByPointer("/update/canaries", 2)).To(Succeed())
+		Expect(ops.GetByPointer("/0/value/canaries")).To(Equal(mustParseYAML("1")))
+	})
+
+	Context("when an operation fails", func() {
+		It("says which operation, and the pointer which failed", func() {
+			_, err := opsfile.Apply(manifest, mustParseYAML(`
+- type: replace
+  path: /name
+  value: renamed
+- type: replace
+  path: /instance_groups/name=worker/instances
+  value: 5
+`))
+			Expect(err).To(MatchError(ContainSubstring("Operation [1] (replace '/instance_groups/name=worker/instances') failed")))
+			Expect(err).To(MatchError(ContainSubstring("at pointer '/instance_groups/name=worker'")))
+		})
+	})
+
+	Context("when the ops file is invalid", func() {
+		DescribeTable("it returns a helpful error", func(ops string, message string) {
+			_, err := opsfile.Apply(manifest, mustParseYAML(ops))
+			Expect(err).To(MatchError(ContainSubstring(message)))
+		},
+			Entry("not a list", `{type: remove, path: /name}`, "must be a list of operations"),
+			Entry("not an object", `[remove]`, "Operation [0] is invalid: an operation must be an object"),
+			Entry("no type", `[{path: /name}]`, "Operation [0] is invalid: missing 'type'"),
+			Entry("no path", `[{type: remove}]`, "Operation [0] is invalid: missing 'path'"),
+			Entry("a non-string path", `[{type: remove, path: 3}]`, "Operation [0] is invalid: 'path' must be a string"),
+			Entry("an unknown type", `[{type: frobnicate, path: /name}]`, "Operation [0] is invalid: unknown type 'frobnicate'"),
+			Entry("a replace without a value", `[{type: replace, path: /name}]`, "Operation [0] is invalid: missing 'value'"),
+			Entry("a remove with a value", `[{type: remove, path: /name, value: 1}]`, "Operation [0] is invalid: a remove operation can't have a 'value'"),
+		)
+	})
+})
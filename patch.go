@@ -0,0 +1,280 @@
+package unstructured
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// The op values of a json patch, as described in
+// https://tools.ietf.org/html/rfc6902#section-4
+const (
+	PatchAdd     = "add"
+	PatchRemove  = "remove"
+	PatchReplace = "replace"
+	PatchMove    = "move"
+	PatchCopy    = "copy"
+	PatchTest    = "test"
+)
+
+// ApplyPatch applies the json patch `patch` to `doc`, and returns the
+// resulting document. `doc` itself is left unchanged. A json patch is a list
+// of operations like `{"op": "add", "path": "/tags/-", "value": "web"}`, as
+// described in https://tools.ietf.org/html/rfc6902
+//
+// Paths may use the same extensions as GetByPointer, so `name=web` selects a
+// list element by the value of one of its fields. Unlike SetByPointer and
+// DeleteByPointer, the operations may replace or remove elements of a list at
+// the root of the document, and replace the whole document.
+//
+// The operations are applied in order. If any of them fails, or a test
+// operation doesn't match, ApplyPatch returns an error saying which operation
+// it was, and none of the patch is applied.
+func ApplyPatch(doc, patch Data) (Data, error) {
+	ops, err := patch.ListValue()
+	if err != nil {
+		return Data{}, fmt.Errorf("A json patch must be a list of operations")
+	}
+	result := deepCopy(doc.data)
+	for i, op := range ops {
+		result, err = applyPatchOp(result, op)
+		if err != nil {
+			return Data{}, fmt.Errorf("Operation [%d] %s", i, err)
+		}
+	}
+	return Data{data: result}, nil
+}
+
+func applyPatchOp(doc interface{}, opData Data) (interface{}, error) {
+	op, ok := opData.data.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("is invalid: an operation must be an object")
+	}
+	name, err := patchField(op, "op")
+	if err != nil {
+		return nil, err
+	}
+	path, err := patchField(op, "path")
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := SplitPointer(path)
+	if err != nil {
+		return nil, fmt.Errorf("(%s '%s') is invalid: %s", name, path, err)
+	}
+	value, hasValue := op["value"]
+	var from []string
+	switch name {
+	case PatchAdd, PatchReplace, PatchTest:
+		if !hasValue {
+			return nil, fmt.Errorf("(%s '%s') is invalid: missing 'value'", name, path)
+		}
+	case PatchMove, PatchCopy:
+		fromPointer, err := patchField(op, "from")
+		if err != nil {
+			return nil, err
+		}
+		if from, err = SplitPointer(fromPointer); err != nil {
+			return nil, fmt.Errorf("(%s '%s') is invalid: %s", name, path, err)
+		}
+	case PatchRemove:
+	default:
+		return nil, fmt.Errorf("is invalid: unknown op '%s'", name)
+	}
+
+	result, err := patchOp(doc, name, tokens, from, value)
+	if err != nil {
+		return nil, fmt.Errorf("(%s '%s') failed: %s", name, path, err)
+	}
+	return result, nil
+}
+
+func patchField(op map[string]interface{}, key string) (string, error) {
+	val, ok := op[key]
+	if !ok {
+		return "", fmt.Errorf("is invalid: missing '%s'", key)
+	}
+	s, ok := val.(string)
+	if !ok {
+		return "", fmt.Errorf("is invalid: '%s' must be a string", key)
+	}
+	return s, nil
+}
+
+func patchOp(doc interface{}, name string, path, from []string, value interface{}) (interface{}, error) {
+	switch name {
+	case PatchAdd:
+		return patchAdd(doc, path, deepCopy(value))
+	case PatchRemove:
+		return patchRemove(doc, path)
+	case PatchReplace:
+		if _, err := patchGet(doc, path); err != nil {
+			return nil, err
+		}
+		return setByTokens(doc, path, deepCopy(value))
+	case PatchMove:
+		if len(from) < len(path) && reflect.DeepEqual(from, path[:len(from)]) {
+			return nil, fmt.Errorf("You can't move a value inside itself")
+		}
+		val, err := patchGet(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		doc, err = patchRemove(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return patchAdd(doc, path, val)
+	case PatchCopy:
+		val, err := patchGet(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return patchAdd(doc, path, deepCopy(val))
+	default:
+		val, err := patchGet(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(val, value) {
+			return nil, fmt.Errorf("Expected %s at pointer '%s', but found %s",
+				compactJSON(value), JoinPointer(path), compactJSON(val))
+		}
+		return doc, nil
+	}
+}
+
+// patchGet returns the value at `tokens`, which must exist.
+func patchGet(doc interface{}, tokens []string) (interface{}, error) {
+	val, found, err := getByTokens(doc, tokens)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("There is nothing at pointer '%s'", JoinPointer(tokens))
+	}
+	return val, nil
+}
+
+// patchAdd adds `val` at `tokens`. Unlike setByTokens, adding at the index of
+// an existing list element inserts before it, rather than replacing it.
+func patchAdd(doc interface{}, tokens []string, val interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return val, nil
+	}
+	parentTokens := tokens[:len(tokens)-1]
+	parent, err := patchGet(doc, parentTokens)
+	if err != nil {
+		return nil, err
+	}
+	list, ok := parent.([]interface{})
+	if !ok {
+		return setByTokens(doc, tokens, val)
+	}
+	// Unlike a pointer to an existing value, an add may use the index just
+	// past the end of the list, to append to it.
+	index := len(list)
+	if tokens[len(tokens)-1] != strconv.Itoa(len(list)) {
+		if index, err = listIndex(list, tokens); err != nil {
+			return nil, err
+		}
+	}
+	if index < 0 {
+		return nil, pointerError(tokens, "Can't insert at index '%s'", tokens[len(tokens)-1])
+	}
+	inserted := make([]interface{}, 0, len(list)+1)
+	inserted = append(inserted, list[:index]...)
+	inserted = append(inserted, val)
+	inserted = append(inserted, list[index:]...)
+	return setByTokens(doc, parentTokens, inserted)
+}
+
+// patchRemove removes the value at `tokens`, which must exist.
+func patchRemove(doc interface{}, tokens []string) (interface{}, error) {
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("You can't remove the whole document")
+	}
+	if _, err := patchGet(doc, tokens); err != nil {
+		return nil, err
+	}
+	return deleteByTokens(doc, tokens)
+}
+
+// ApplyMergePatch applies the json merge patch `patch` to `doc`, and returns
+// the resulting document. `doc` itself is left unchanged. A merge patch looks
+// like the document it changes: each field in the patch replaces the field
+// of the same name in the document, except that objects are merged
+// recursively, and a null field removes the field from the document. See
+// https://tools.ietf.org/html/rfc7396 for details.
+//
+// This is close to Merge with NullMeansDelete, but follows the RFC exactly:
+// for example, nulls inside lists in the patch are kept.
+func ApplyMergePatch(doc, patch Data) Data {
+	return Data{data: mergePatch(deepCopy(doc.data), patch.data)}
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return deepCopy(patch)
+	}
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = map[string]interface{}{}
+	}
+	for key, val := range p {
+		if val == nil {
+			delete(t, key)
+		} else {
+			t[key] = mergePatch(t[key], val)
+		}
+	}
+	return t
+}
+
+// DiffPatch compares two documents, and returns a json patch which turns
+// `from` into `to` when given to ApplyPatch. It accepts the same options as
+// Diff. Differences which Diff would ignore are left out of the patch, and
+// lists given to UnorderedListsAt may end up in a different order from the
+// list in `to`.
+func DiffPatch(from, to Data, opts ...DiffOption) (Data, error) {
+	diffs, err := Diff(from, to, opts...)
+	if err != nil {
+		return Data{}, err
+	}
+
+	// Removing a list element moves the ones after it, so removals are done
+	// first, working backwards through the document. Additions to a list then
+	// come in increasing order of index, each landing where `to` has it.
+	var removals, others []Difference
+	for _, diff := range diffs {
+		if diff.Type == Removed {
+			removals = append(removals, diff)
+		} else {
+			others = append(others, diff)
+		}
+	}
+	sort.SliceStable(removals, func(i, j int) bool {
+		iTokens, _ := SplitPointer(removals[i].Pointer)
+		jTokens, _ := SplitPointer(removals[j].Pointer)
+		return tokensBefore(from.data, jTokens, iTokens)
+	})
+
+	patch := []interface{}{}
+	for _, diff := range append(removals, others...) {
+		op := map[string]interface{}{"path": diff.Pointer}
+		switch diff.Type {
+		case Removed:
+			op["op"] = PatchRemove
+		case Added:
+			op["op"] = PatchAdd
+			op["value"] = deepCopy(diff.To.data)
+		default:
+			op["op"] = PatchReplace
+			op["value"] = deepCopy(diff.To.data)
+		}
+		patch = append(patch, op)
+	}
+	return Data{data: patch}, nil
+}
@@ -0,0 +1,211 @@
+package unstructured_test
+
+import (
+	"github.com/totherme/unstructured"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Patches", func() {
+	var doc unstructured.Data
+
+	BeforeEach(func() {
+		doc = mustParseYAML(`
+name: my-deployment
+tags: [a, b]
+instance_groups:
+- name: web
+  instances: 2
+`)
+	})
+
+	Describe("ApplyPatch", func() {
+		apply := func(patch string) unstructured.Data {
+			result, err := unstructured.ApplyPatch(doc, mustParseYAML(patch))
+			Expect(err).NotTo(HaveOccurred())
+			return result
+		}
+
+		applyErr := func(patch string) error {
+			_, err := unstructured.ApplyPatch(doc, mustParseYAML(patch))
+			Expect(err).To(HaveOccurred())
+			return err
+		}
+
+		It("adds values, inserting into lists", func() {
+			Expect(apply(`
+- {op: add, path: /tags/1, value: c}
+- {op: add, path: /tags/-, value: d}
+- {op: add, path: /update, value: {canaries: 1}}
+`).RawValue()).To(Equal(mustParseYAML(`
+name: my-deployment
+tags: [a, c, b, d]
+update: {canaries: 1}
+instance_groups: [{name: web, instances: 2}]
+`).RawValue()))
+		})
+
+		It("removes and replaces values", func() {
+			Expect(apply(`
+- {op: remove, path: /tags/0}
+- {op: replace, path: /instance_groups/name=web/instances, value: 3}
+`).RawValue()).To(Equal(mustParseYAML(`
+name: my-deployment
+tags: [b]
+instance_groups: [{name: web, instances: 3}]
+`).RawValue()))
+		})
+
+		It("moves and copies values", func() {
+			Expect(apply(`
+- {op: copy, from: /name, path: /instance_groups/0/deployment}
+- {op: move, from: /tags, path: /labels}
+`).RawValue()).To(Equal(mustParseYAML(`
+name: my-deployment
+labels: [a, b]
+instance_groups: [{name: web, instances: 2, deployment: my-deployment}]
+`).RawValue()))
+		})
+
+		It("can replace the whole document, and edit lists at the root", func() {
+			Expect(apply(`[{op: replace, path: "", value: [1, 2]}, {op: remove, path: /0}, {op: add, path: /0, value: 0}]`).
+				RawValue()).To(Equal([]interface{}{0.0, 2.0}))
+		})
+
+		It("checks test operations", func() {
+			Expect(apply(`[{op: test, path: /tags, value: [a, b]}]`).RawValue()).To(Equal(doc.RawValue()))
+			Expect(applyErr(`[{op: test, path: /tags/0, value: b}]`)).
+				To(MatchError(`Operation [0] (test '/tags/0') failed: Expected "b" at pointer '/tags/0', but found "a"`))
+		})
+
+		It("applies none of the patch if an operation fails", func() {
+			Expect(applyErr(`[{op: remove, path: /name}, {op: remove, path: /missing}]`)).
+				To(MatchError(`Operation [1] (remove '/missing') failed: Object has no key 'missing' at pointer '/missing'`))
+			Expect(doc.HasKey("name")).To(BeTrue())
+		})
+
+		It("requires the targets of replace and the parents of add to exist", func() {
+			Expect(applyErr(`[{op: replace, path: /update, value: {}}]`)).
+				To(MatchError(ContainSubstring("Object has no key 'update'")))
+			Expect(applyErr(`[{op: add, path: /update/canaries, value: 1}]`)).
+				To(MatchError(ContainSubstring("Object has no key 'update'")))
+		})
+
+		It("won't move a value inside itself", func() {
+			Expect(applyErr(`[{op: move, from: /tags, path: /tags/0}]`)).
+				To(MatchError(ContainSubstring("You can't move a value inside itself")))
+		})
+
+		It("returns an error for malformed patches", func() {
+			Expect(applyErr(`{op: remove, path: /name}`)).To(MatchError("A json patch must be a list of operations"))
+			Expect(applyErr(`[{op: frobnicate, path: /name}]`)).To(MatchError("Operation [0] is invalid: unknown op 'frobnicate'"))
+			Expect(applyErr(`[{path: /name}]`)).To(MatchError("Operation [0] is invalid: missing 'op'"))
+			Expect(applyErr(`[{op: add, path: /name}]`)).To(MatchError("Operation [0] (add '/name') is invalid: missing 'value'"))
+			Expect(applyErr(`[{op: copy, path: /name}]`)).To(MatchError("Operation [0] is invalid: missing 'from'"))
+		})
+	})
+
+	Describe("ApplyMergePatch", func() {
+		It("merges objects, replaces everything else and removes null fields", func() {
+			result := unstructured.ApplyMergePatch(doc, mustParseYAML(`
+name: null
+tags: [c, null]
+update: {canaries: 1, serial: null}
+`))
+			Expect(result.RawValue()).To(Equal(mustParseYAML(`
+tags: [c, null]
+update: {canaries: 1}
+instance_groups: [{name: web, instances: 2}]
+`).RawValue()))
+			Expect(doc.HasKey("name")).To(BeTrue())
+		})
+
+		It("replaces the whole document with a patch which isn't an object", func() {
+			Expect(unstructured.ApplyMergePatch(doc, mustParseYAML(`[1]`)).RawValue()).To(Equal([]interface{}{1.0}))
+		})
+	})
+
+	Describe("DiffPatch", func() {
+		It("makes a patch which turns one document into the other", func() {
+			to := mustParseYAML(`
+name: production
+tags: [a]
+update: {canaries: 1}
+instance_groups:
+- name: web
+  instances: 3
+`)
+			patch, err := unstructured.DiffPatch(doc, to)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(patch.RawValue()).To(Equal(mustParseYAML(`
+- {op: remove, path: /tags/1}
+- {op: replace, path: /instance_groups/0/instances, value: 3}
+- {op: replace, path: /name, value: production}
+- {op: add, path: /update, value: {canaries: 1}}
+`).RawValue()))
+
+			result, err := unstructured.ApplyPatch(doc, patch)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RawValue()).To(Equal(to.RawValue()))
+		})
+
+		It("makes patches which ApplyPatch can apply", func() {
+			for _, c := range []struct{ from, to string }{
+				{`{a: [1, 2, 3]}`, `{a: [1, 2, 3, 4, 5]}`},
+				{`{a: [1, 2, 3, 4, 5]}`, `{a: [1, 2]}`},
+				{`[1, 2]`, `[1, 2, [3]]`},
+				{`{a: [{b: [1]}, 2]}`, `{a: [{b: [1, 2, 3]}, 2, 3]}`},
+			} {
+				from, to := mustParseYAML(c.from), mustParseYAML(c.to)
+				patch, err := unstructured.DiffPatch(from, to)
+				Expect(err).NotTo(HaveOccurred())
+				result, err := unstructured.ApplyPatch(from, patch)
+				Expect(err).NotTo(HaveOccurred(), "patching %s to %s", c.from, c.to)
+				Expect(result.RawValue()).To(Equal(to.RawValue()), "patching %s to %s", c.from, c.to)
+			}
+		})
+
+		It("makes patches which ApplyPatch can apply to unordered lists", func() {
+			for _, c := range []struct{ from, to string }{
+				{`{a: [1, 2, 3]}`, `{a: [3, 1, 2, 4, 5]}`},
+				{`{a: [1, 2, 3]}`, `{a: [4, 2]}`},
+				{`{a: [1, 2]}`, `{a: [3, 1, 4, 2, 5]}`},
+			} {
+				from, to := mustParseYAML(c.from), mustParseYAML(c.to)
+				patch, err := unstructured.DiffPatch(from, to, unstructured.UnorderedListsAt("/a"))
+				Expect(err).NotTo(HaveOccurred())
+				result, err := unstructured.ApplyPatch(from, patch)
+				Expect(err).NotTo(HaveOccurred(), "patching %s to %s", c.from, c.to)
+				diffs, err := unstructured.Diff(result, to, unstructured.UnorderedListsAt("/a"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(diffs).To(BeEmpty(), "patching %s to %s", c.from, c.to)
+			}
+		})
+
+		It("removes list elements from the end first", func() {
+			from := mustParseYAML(`{list: [0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11]}`)
+			to := mustParseYAML(`{list: [0]}`)
+			patch, err := unstructured.DiffPatch(from, to)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(patch.UnsafeListValue()[0].F("path").UnsafeStringValue()).To(Equal("/list/11"))
+
+			result, err := unstructured.ApplyPatch(from, patch)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RawValue()).To(Equal(to.RawValue()))
+		})
+
+		It("accepts DiffOptions", func() {
+			to := mustParseYAML(`{name: other, tags: [c, b], instance_groups: [{name: web, instances: 2}]}`)
+			patch, err := unstructured.DiffPatch(doc, to, unstructured.IgnoreAt("/name"), unstructured.UnorderedListsAt("/tags"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(patch.RawValue()).To(Equal(mustParseYAML(`
+- {op: remove, path: /tags/0}
+- {op: add, path: /tags/0, value: c}
+`).RawValue()))
+
+			_, err = unstructured.DiffPatch(doc, to, unstructured.IgnoreAt("name"))
+			Expect(err).To(MatchError(ContainSubstring("Invalid pattern 'name'")))
+		})
+	})
+})
@@ -0,0 +1,345 @@
+package unstructured
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SplitPointer breaks a json pointer into its unescaped reference tokens, so
+// "/a~1b/0" becomes "a/b" and "0". The empty pointer refers to the whole
+// document, and has no tokens. Any other pointer must start with a "/".
+// Tokens are returned as written, so extensions such as `name=web?` are left
+// for GetByPointer and friends to interpret.
+func SplitPointer(p string) ([]string, error) {
+	if p == "" {
+		return nil, nil
+	}
+	if p[0] != '/' {
+		return nil, fmt.Errorf("JSON pointer must be empty or start with a \"/\"")
+	}
+	tokens := strings.Split(p[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = unescapePointerToken(token)
+	}
+	return tokens, nil
+}
+
+// JoinPointer builds a json pointer out of unescaped reference tokens, and is
+// the reverse of SplitPointer.
+func JoinPointer(tokens []string) string {
+	var b strings.Builder
+	for _, token := range tokens {
+		b.WriteString("/")
+		b.WriteString(EscapePointerToken(token))
+	}
+	return b.String()
+}
+
+// EscapePointerToken escapes a single reference token, so that it can be
+// appended to a json pointer after a "/". See
+// https://tools.ietf.org/html/rfc6901#section-3
+func EscapePointerToken(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+// unescapePointerToken reverses EscapePointerToken. Both escapes are decoded
+// in a single pass, so that '~01' becomes '~1' rather than '/'.
+func unescapePointerToken(token string) string {
+	return strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+}
+
+// optionalMarker may be appended to a reference token to mark it as optional.
+// As in BOSH ops files, every segment after an optional one is optional too.
+// When looking up a pointer, a missing optional segment means there is
+// nothing at the pointer, rather than an error. When setting, it means any
+// missing object or list element should be created on the way.
+const optionalMarker = "?"
+
+// afterLastMarker is the reference token which refers to the (nonexistent)
+// element after the end of a list. Setting it appends to the list.
+const afterLastMarker = "-"
+
+// getByTokens follows the reference tokens `tokens` down from `node`. If an
+// optional segment doesn't match anything, `found` is false and there's no
+// error.
+func getByTokens(node interface{}, tokens []string) (val interface{}, found bool, err error) {
+	for i, token := range tokens {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			key, ok := objectKey(n, token)
+			if !ok {
+				if isOptionalPath(tokens[:i+1]) {
+					return nil, false, nil
+				}
+				return nil, false, pointerError(tokens[:i+1], "Object has no key '%s'", token)
+			}
+			node = n[key]
+		case []interface{}:
+			index, err := listIndex(n, tokens[:i+1])
+			if err != nil {
+				return nil, false, err
+			}
+			if index < 0 {
+				return nil, false, nil
+			}
+			if index == len(n) {
+				return nil, false, pointerError(tokens[:i+1], "Out of bound array[0,%d] index '%s'", len(n), token)
+			}
+			node = n[index]
+		default:
+			return nil, false, pointerError(tokens[:i+1], "Invalid token reference '%s'", token)
+		}
+	}
+	return node, true, nil
+}
+
+// setByTokens sets the value at `tokens` below `node` to `val`, and returns
+// the new value of `node`. Objects and lists are updated in place, but since
+// appending to a list may move it, callers must store the returned value
+// wherever they found `node`.
+func setByTokens(node interface{}, tokens []string, val interface{}) (interface{}, error) {
+	return setByTokensFrom(node, tokens, 0, val)
+}
+
+func setByTokensFrom(node interface{}, tokens []string, i int, val interface{}) (interface{}, error) {
+	if i == len(tokens) {
+		return val, nil
+	}
+	token := tokens[i]
+	isLast := i == len(tokens)-1
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		key, ok := objectKey(n, token)
+		var child interface{}
+		if ok {
+			child = n[key]
+		} else {
+			key = strings.TrimSuffix(token, optionalMarker)
+			if !isLast {
+				if !isOptionalPath(tokens[:i+1]) {
+					return nil, pointerError(tokens[:i+1], "Object has no key '%s'", token)
+				}
+				child = newContainerFor(tokens[i+1])
+			}
+		}
+		newChild, err := setByTokensFrom(child, tokens, i+1, val)
+		if err != nil {
+			return nil, err
+		}
+		n[key] = newChild
+		return n, nil
+
+	case []interface{}:
+		index, err := listIndex(n, tokens[:i+1])
+		if err != nil {
+			return nil, err
+		}
+		var child interface{}
+		switch {
+		case index >= 0 && index < len(n):
+			child = n[index]
+		case index == len(n) && !isLast:
+			child = newContainerFor(tokens[i+1])
+		case index < 0:
+			key, value, isSelector := splitSelector(token)
+			if !isSelector {
+				return nil, pointerError(tokens[:i+1], "Out of bound array[0,%d] index '%s'", len(n), token)
+			}
+			child = map[string]interface{}{key: value}
+			index = len(n)
+		}
+		newChild, err := setByTokensFrom(child, tokens, i+1, val)
+		if err != nil {
+			return nil, err
+		}
+		if index == len(n) {
+			return append(n, newChild), nil
+		}
+		n[index] = newChild
+		return n, nil
+
+	default:
+		return nil, pointerError(tokens[:i+1], "Invalid token reference '%s'", token)
+	}
+}
+
+// objectKey finds the key of `ob` referred to by `token`. A key which
+// literally matches the token always wins, so that keys which happen to end
+// in the optional marker can still be addressed.
+func objectKey(ob map[string]interface{}, token string) (string, bool) {
+	if _, ok := ob[token]; ok {
+		return token, true
+	}
+	if isOptional(token) {
+		key := strings.TrimSuffix(token, optionalMarker)
+		if _, ok := ob[key]; ok {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+// listIndex finds the index into `list` referred to by the last of `tokens`.
+// It returns len(list) for the after-last marker `-`, and -1 if an optional
+// token doesn't match any element.
+//
+// As well as plain indexes, a token may be a selector like `name=web`, which
+// refers to the single object element of the list whose `name` field is
+// `web`.
+func listIndex(list []interface{}, tokens []string) (int, error) {
+	token := tokens[len(tokens)-1]
+	optional := isOptionalPath(tokens)
+	name := strings.TrimSuffix(token, optionalMarker)
+
+	if name == afterLastMarker {
+		return len(list), nil
+	}
+
+	if index, err := strconv.Atoi(name); err == nil {
+		if index < 0 || index >= len(list) {
+			if optional {
+				return -1, nil
+			}
+			return 0, pointerError(tokens, "Out of bound array[0,%d] index '%d'", len(list), index)
+		}
+		return index, nil
+	}
+
+	key, value, ok := splitSelector(token)
+	if !ok {
+		return 0, pointerError(tokens, "Invalid array index '%s'", token)
+	}
+	match := fieldEquals(key, value)
+	index := -1
+	matches := 0
+	for i, elem := range list {
+		if match(Data{data: elem}) {
+			index = i
+			matches++
+		}
+	}
+	if matches == 0 && optional {
+		return -1, nil
+	}
+	if matches != 1 {
+		return 0, pointerError(tokens, "Expected to find exactly one list element matching '%s' but found %d", name, matches)
+	}
+	return index, nil
+}
+
+// splitSelector splits a selector token like `name=web` (or `name=web?`) into
+// its key and value.
+func splitSelector(token string) (key string, value string, ok bool) {
+	return strings.Cut(strings.TrimSuffix(token, optionalMarker), "=")
+}
+
+// fieldEquals returns an ElementMatcher which matches objects whose field
+// `key` has the value `value`. Numbers and bools are compared by their json
+// representation, so `id=1` matches `{"id": 1}`.
+func fieldEquals(key, value string) ElementMatcher {
+	return func(elem Data) bool {
+		if !elem.IsOb() || !elem.HasKey(key) {
+			return false
+		}
+		field := elem.F(key)
+		if field.IsString() {
+			return field.UnsafeStringValue() == value
+		}
+		if field.IsNum() || field.IsBool() {
+			encoded, err := json.Marshal(field.RawValue())
+			return err == nil && string(encoded) == value
+		}
+		return false
+	}
+}
+
+// newContainerFor returns an empty container suitable for looking up the
+// token `next` in: a list if `next` looks like a list index, and an object
+// otherwise.
+func newContainerFor(next string) interface{} {
+	name := strings.TrimSuffix(next, optionalMarker)
+	if _, err := strconv.Atoi(name); err == nil || name == afterLastMarker || strings.Contains(name, "=") {
+		return []interface{}{}
+	}
+	return map[string]interface{}{}
+}
+
+func isOptional(token string) bool {
+	return strings.HasSuffix(token, optionalMarker)
+}
+
+// isOptionalPath reports whether the last of `tokens` is optional, either
+// because it is marked as optional itself, or because one before it is.
+func isOptionalPath(tokens []string) bool {
+	for _, token := range tokens {
+		if isOptional(token) {
+			return true
+		}
+	}
+	return false
+}
+
+func pointerError(tokens []string, format string, args ...interface{}) error {
+	return fmt.Errorf("%s at pointer '%s'", fmt.Sprintf(format, args...), JoinPointer(tokens))
+}
+
+// deleteByTokens removes the value at `tokens` below `node`, and returns the
+// new value of `node`. As with setByTokens, callers must store the returned
+// value wherever they found `node`, since removing a list element makes a
+// shorter list.
+func deleteByTokens(node interface{}, tokens []string) (interface{}, error) {
+	return deleteByTokensFrom(node, tokens, 0)
+}
+
+func deleteByTokensFrom(node interface{}, tokens []string, i int) (interface{}, error) {
+	token := tokens[i]
+	isLast := i == len(tokens)-1
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		key, ok := objectKey(n, token)
+		if !ok {
+			if isOptionalPath(tokens[:i+1]) {
+				return n, nil
+			}
+			return nil, pointerError(tokens[:i+1], "Object has no key '%s'", token)
+		}
+		if isLast {
+			delete(n, key)
+			return n, nil
+		}
+		newChild, err := deleteByTokensFrom(n[key], tokens, i+1)
+		if err != nil {
+			return nil, err
+		}
+		n[key] = newChild
+		return n, nil
+
+	case []interface{}:
+		index, err := listIndex(n, tokens[:i+1])
+		if err != nil {
+			return nil, err
+		}
+		if index < 0 {
+			return n, nil
+		}
+		if index == len(n) {
+			return nil, pointerError(tokens[:i+1], "Out of bound array[0,%d] index '%s'", len(n), token)
+		}
+		if isLast {
+			return append(n[:index:index], n[index+1:]...), nil
+		}
+		newChild, err := deleteByTokensFrom(n[index], tokens, i+1)
+		if err != nil {
+			return nil, err
+		}
+		n[index] = newChild
+		return n, nil
+
+	default:
+		return nil, pointerError(tokens[:i+1], "Invalid token reference '%s'", token)
+	}
+}
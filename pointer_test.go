@@ -0,0 +1,313 @@
+package unstructured_test
+
+import (
+	"github.com/totherme/unstructured"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Extended pointers", func() {
+	var manifest unstructured.Data
+
+	BeforeEach(func() {
+		var err error
+		manifest, err = unstructured.ParseYAML(`
+instance_groups:
+- name: web
+  instances: 2
+  jobs:
+  - name: nginx
+    properties:
+      port: 80
+  - name: route_registrar
+- name: db
+  instances: 1
+  jobs:
+  - name: postgres
+- name: db
+  instances: 1
+literal=key: for objects
+what?: a key with a question mark
+`)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Describe("GetByPointer", func() {
+		It("selects list elements by field value", func() {
+			port, err := manifest.GetByPointer("/instance_groups/name=web/jobs/name=nginx/properties/port")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(port.UnsafeNumValue()).To(BeEquivalentTo(80))
+		})
+
+		It("compares numbers by their json representation", func() {
+			name, err := manifest.GetByPointer("/instance_groups/instances=2/name")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(name.UnsafeStringValue()).To(Equal("web"))
+		})
+
+		It("treats segments with an = as plain keys when the parent is an object", func() {
+			val, err := manifest.GetByPointer("/literal=key")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(val.UnsafeStringValue()).To(Equal("for objects"))
+		})
+
+		It("prefers keys which literally end in a question mark", func() {
+			val, err := manifest.GetByPointer("/what?")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(val.UnsafeStringValue()).To(Equal("a key with a question mark"))
+		})
+
+		It("follows optional segments which exist", func() {
+			name, err := manifest.GetByPointer("/instance_groups?/name=web?/jobs/0/name")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(name.UnsafeStringValue()).To(Equal("nginx"))
+		})
+
+		It("returns null without an error when an optional segment is missing", func() {
+			val, err := manifest.GetByPointer("/instance_groups/name=worker?/jobs")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(val.IsNull()).To(BeTrue())
+
+			val, err = manifest.GetByPointer("/variables?/0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(val.IsNull()).To(BeTrue())
+		})
+
+		It("treats the segments after an optional one as optional", func() {
+			val, err := manifest.GetByPointer("/instance_groups?/name=web/jobs/name=haproxy/properties")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(val.IsNull()).To(BeTrue())
+		})
+
+		Context("when a selector matches nothing", func() {
+			It("returns an error naming the failing pointer", func() {
+				_, err := manifest.GetByPointer("/instance_groups/name=worker/jobs")
+				Expect(err).To(MatchError(ContainSubstring("Expected to find exactly one list element matching 'name=worker' but found 0")))
+				Expect(err).To(MatchError(ContainSubstring("at pointer '/instance_groups/name=worker'")))
+			})
+		})
+
+		Context("when a selector matches more than one element", func() {
+			It("returns an error, even if the segment is optional", func() {
+				_, err := manifest.GetByPointer("/instance_groups/name=db?")
+				Expect(err).To(MatchError(ContainSubstring("Expected to find exactly one list element matching 'name=db' but found 2")))
+			})
+		})
+
+		Context("when a segment is neither an index nor a selector", func() {
+			It("returns an error", func() {
+				_, err := manifest.GetByPointer("/instance_groups/web")
+				Expect(err).To(MatchError(ContainSubstring("Invalid array index 'web'")))
+			})
+		})
+	})
+
+	Describe("HasPointer", func() {
+		It("understands selectors", func() {
+			Expect(manifest.HasPointer("/instance_groups/name=web/jobs/name=route_registrar")).To(BeTrue())
+			Expect(manifest.HasPointer("/instance_groups/name=web/jobs/name=haproxy")).To(BeFalse())
+		})
+
+		It("reports missing optional segments as missing", func() {
+			Expect(manifest.HasPointer("/instance_groups/name=worker?")).To(BeFalse())
+		})
+	})
+
+	Describe("SetByPointer", func() {
+		It("replaces values at plain pointers", func() {
+			Expect(manifest.SetByPointer("/instance_groups/0/instances", 3)).To(Succeed())
+			Expect(manifest.F("instance_groups").UnsafeListValue()[0].F("instances").RawValue()).To(Equal(3))
+		})
+
+		It("replaces values at selected list elements", func() {
+			Expect(manifest.SetByPointer("/instance_groups/name=web/jobs/name=nginx/properties/port", 8080)).To(Succeed())
+			port, err := manifest.GetByPointer("/instance_groups/0/jobs/0/properties/port")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(port.RawValue()).To(Equal(8080))
+		})
+
+		It("creates new keys on existing objects", func() {
+			Expect(manifest.SetByPointer("/instance_groups/name=web/azs", []interface{}{"z1"})).To(Succeed())
+			Expect(manifest.HasPointer("/instance_groups/0/azs/0")).To(BeTrue())
+		})
+
+		It("appends to lists with -", func() {
+			Expect(manifest.SetByPointer("/instance_groups/name=db/jobs/-", map[string]interface{}{"name": "backup"})).
+				NotTo(Succeed(), "two instance groups are called db")
+			Expect(manifest.SetByPointer("/instance_groups/name=web/jobs/-", map[string]interface{}{"name": "backup"})).To(Succeed())
+			name, err := manifest.GetByPointer("/instance_groups/0/jobs/2/name")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(name.UnsafeStringValue()).To(Equal("backup"))
+		})
+
+		It("creates missing optional segments", func() {
+			Expect(manifest.SetByPointer("/instance_groups/name=web/jobs/name=haproxy?/properties?/port", 443)).To(Succeed())
+			port, err := manifest.GetByPointer("/instance_groups/name=web/jobs/name=haproxy/properties/port")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(port.RawValue()).To(Equal(443))
+
+			Expect(manifest.SetByPointer("/variables?/-", "a-variable")).To(Succeed())
+			Expect(manifest.F("variables").UnsafeListValue()).To(HaveLen(1))
+		})
+
+		It("creates the segments after an optional one, too", func() {
+			Expect(manifest.SetByPointer("/update?/canaries/max", 2)).To(Succeed())
+			val, err := manifest.GetByPointer("/update/canaries/max")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(val.RawValue()).To(Equal(2))
+		})
+
+		Context("when a non-optional parent is missing", func() {
+			It("returns an error naming the failing pointer", func() {
+				err := manifest.SetByPointer("/instance_groups/name=web/properties/port", 443)
+				Expect(err).To(MatchError(ContainSubstring("Object has no key 'properties' at pointer '/instance_groups/name=web/properties'")))
+			})
+		})
+
+		Context("when the pointer is empty", func() {
+			It("returns an error", func() {
+				Expect(manifest.SetByPointer("", 42)).To(MatchError(ContainSubstring("can't set the root")))
+			})
+		})
+
+		Context("when the pointer is invalid", func() {
+			It("returns a helpful error message", func() {
+				Expect(manifest.SetByPointer("instance_groups", 42)).
+					To(MatchError(ContainSubstring("JSON pointer must be empty or start with a \"/\"")))
+			})
+		})
+
+		Context("when the Data is a list", func() {
+			var list unstructured.Data
+
+			BeforeEach(func() {
+				var err error
+				list, err = unstructured.ParseJSON(`[{"name": "a"}, {"name": "b"}]`)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("can update its elements", func() {
+				Expect(list.SetByPointer("/name=b/value", 2)).To(Succeed())
+				Expect(list.UnsafeListValue()[1].F("value").RawValue()).To(Equal(2))
+			})
+
+			It("can't append to it", func() {
+				Expect(list.SetByPointer("/-", "c")).To(MatchError(ContainSubstring("can't add elements to a list at the root")))
+				Expect(list.SetByPointer("/name=c?/value", 3)).To(MatchError(ContainSubstring("can't add elements to a list at the root")))
+			})
+		})
+	})
+
+	Describe("DeleteByPointer", func() {
+		It("removes keys from objects", func() {
+			Expect(manifest.DeleteByPointer("/instance_groups/name=web/jobs/name=nginx/properties")).To(Succeed())
+			Expect(manifest.HasPointer("/instance_groups/0/jobs/0/properties")).To(BeFalse())
+			Expect(manifest.HasPointer("/instance_groups/0/jobs/0/name")).To(BeTrue())
+		})
+
+		It("closes up lists behind removed elements", func() {
+			Expect(manifest.DeleteByPointer("/instance_groups/name=web/jobs/0")).To(Succeed())
+			jobs, err := manifest.GetByPointer("/instance_groups/0/jobs")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jobs.UnsafeListValue()).To(HaveLen(1))
+			Expect(jobs.UnsafeListValue()[0].F("name").UnsafeStringValue()).To(Equal("route_registrar"))
+		})
+
+		It("does nothing when an optional segment is missing", func() {
+			Expect(manifest.DeleteByPointer("/instance_groups/name=worker?")).To(Succeed())
+			Expect(manifest.DeleteByPointer("/variables?/0")).To(Succeed())
+			Expect(manifest.F("instance_groups").UnsafeListValue()).To(HaveLen(3))
+		})
+
+		Context("when the value doesn't exist", func() {
+			It("returns an error naming the failing pointer", func() {
+				Expect(manifest.DeleteByPointer("/instance_groups/name=web/azs")).
+					To(MatchError(ContainSubstring("Object has no key 'azs' at pointer '/instance_groups/name=web/azs'")))
+			})
+		})
+
+		Context("when the pointer is empty", func() {
+			It("returns an error", func() {
+				Expect(manifest.DeleteByPointer("")).To(MatchError(ContainSubstring("can't delete the root")))
+			})
+		})
+
+		Context("when the Data is a list", func() {
+			It("can't remove its elements", func() {
+				list, err := unstructured.ParseJSON(`[{"name": "a"}, {"name": "b"}]`)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(list.DeleteByPointer("/0")).To(MatchError(ContainSubstring("can't remove elements from a list at the root")))
+				Expect(list.DeleteByPointer("/0/name")).To(Succeed())
+				Expect(list.UnsafeListValue()[0].HasKey("name")).To(BeFalse())
+			})
+		})
+	})
+
+	Describe("WithPointerSet and WithPointerDeleted", func() {
+		var list unstructured.Data
+
+		BeforeEach(func() {
+			var err error
+			list, err = unstructured.ParseJSON(`[{"name": "a"}, {"name": "b"}]`)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("change values like SetByPointer and DeleteByPointer", func() {
+			result, err := manifest.WithPointerSet("/instance_groups/name=web/instances", 3)
+			Expect(err).NotTo(HaveOccurred())
+			instances, err := result.GetByPointer("/instance_groups/0/instances")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(instances.RawValue()).To(Equal(3))
+		})
+
+		It("can replace the whole document", func() {
+			result, err := list.WithPointerSet("", "replaced")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RawValue()).To(Equal("replaced"))
+		})
+
+		It("can add to and remove from a list at the root", func() {
+			result, err := list.WithPointerSet("/-", "c")
+			Expect(err).NotTo(HaveOccurred())
+			result, err = result.WithPointerSet("/name=d?/value", 4)
+			Expect(err).NotTo(HaveOccurred())
+			result, err = result.WithPointerDeleted("/name=a")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RawValue()).To(Equal([]interface{}{
+				map[string]interface{}{"name": "b"},
+				"c",
+				map[string]interface{}{"name": "d", "value": 4},
+			}))
+		})
+
+		It("return errors like SetByPointer and DeleteByPointer", func() {
+			_, err := list.WithPointerSet("/name=c/value", 3)
+			Expect(err).To(MatchError(ContainSubstring("Expected to find exactly one list element matching 'name=c'")))
+			_, err = list.WithPointerDeleted("")
+			Expect(err).To(MatchError(ContainSubstring("can't delete the root")))
+			_, err = list.WithPointerDeleted("0")
+			Expect(err).To(MatchError(ContainSubstring("JSON pointer must be empty or start with a \"/\"")))
+		})
+	})
+
+	Describe("SplitPointer and JoinPointer", func() {
+		It("unescape and escape reference tokens", func() {
+			tokens, err := unstructured.SplitPointer("/a~1b/~01/name=web?")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tokens).To(Equal([]string{"a/b", "~1", "name=web?"}))
+			Expect(unstructured.JoinPointer(tokens)).To(Equal("/a~1b/~01/name=web?"))
+			Expect(unstructured.EscapePointerToken("a/~b")).To(Equal("a~1~0b"))
+		})
+
+		It("treat the empty pointer as the whole document", func() {
+			Expect(unstructured.SplitPointer("")).To(BeEmpty())
+			Expect(unstructured.JoinPointer(nil)).To(Equal(""))
+		})
+
+		It("reject pointers which don't start with a slash", func() {
+			_, err := unstructured.SplitPointer("a/b")
+			Expect(err).To(MatchError(ContainSubstring("JSON pointer must be empty or start with a \"/\"")))
+		})
+	})
+})
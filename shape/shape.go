@@ -0,0 +1,176 @@
+// Package shape checks that unstructured data has the shape your code
+// expects, without the weight of a full JSON Schema.
+//
+// Shapes are built in Go, so they sit next to the code which relies on them
+// and double as documentation of what that code expects:
+//
+//	deployment := shape.Object(
+//		shape.Field("name", shape.String()),
+//		shape.OptionalField("port", shape.Num()),
+//		shape.Field("tags", shape.ListOf(shape.String())),
+//	)
+//	for _, err := range deployment.Validate(data) {
+//		fmt.Println(err)
+//	}
+//
+// Objects may contain fields which their shape doesn't mention. For richer
+// checks, see the jsonschema package.
+package shape
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/totherme/unstructured"
+)
+
+// A Shape describes the structure of some unstructured data. The zero Shape
+// accepts anything.
+type Shape struct {
+	typ    unstructured.Kind
+	fields []FieldShape
+	elem   *Shape
+}
+
+// A FieldShape describes one field of an object.
+type FieldShape struct {
+	name     string
+	shape    Shape
+	optional bool
+}
+
+// String is the shape of a string.
+func String() Shape {
+	return Shape{typ: unstructured.KindString}
+}
+
+// Num is the shape of a number.
+func Num() Shape {
+	return Shape{typ: unstructured.KindNum}
+}
+
+// Bool is the shape of a bool.
+func Bool() Shape {
+	return Shape{typ: unstructured.KindBool}
+}
+
+// Null is the shape of null.
+func Null() Shape {
+	return Shape{typ: unstructured.KindNull}
+}
+
+// Any is the shape of any data at all.
+func Any() Shape {
+	return Shape{}
+}
+
+// ListOf is the shape of a list whose elements all have the shape `elem`.
+func ListOf(elem Shape) Shape {
+	return Shape{typ: unstructured.KindList, elem: &elem}
+}
+
+// Object is the shape of an object with the given fields. It may also have
+// other fields.
+func Object(fields ...FieldShape) Shape {
+	return Shape{typ: unstructured.KindOb, fields: fields}
+}
+
+// Field describes a field which must be present, and must have the shape
+// `shape`.
+func Field(name string, shape Shape) FieldShape {
+	return FieldShape{name: name, shape: shape}
+}
+
+// OptionalField describes a field which may be absent, but which must have
+// the shape `shape` if it is present.
+func OptionalField(name string, shape Shape) FieldShape {
+	return FieldShape{name: name, shape: shape, optional: true}
+}
+
+// Validate checks `d` against the shape, and returns an error for every
+// place where it doesn't fit. Each error gives the json pointer of the
+// offending value. If `d` has the right shape, Validate returns an empty
+// slice.
+func (s Shape) Validate(d unstructured.Data) []error {
+	return s.validate(d, "")
+}
+
+func (s Shape) validate(d unstructured.Data, pointer string) []error {
+	if s.typ == "" {
+		return nil
+	}
+	if d.Type() != s.typ {
+		return []error{fmt.Errorf("Expected %s at pointer '%s', but found %s",
+			describeType(s.typ), pointer, describeValue(d.RawValue()))}
+	}
+
+	var errs []error
+	switch s.typ {
+	case unstructured.KindOb:
+		for _, field := range s.fields {
+			fieldPointer := pointer + "/" + unstructured.EscapePointerToken(field.name)
+			if !d.HasKey(field.name) {
+				if !field.optional {
+					errs = append(errs, fmt.Errorf("Missing required field at pointer '%s'", fieldPointer))
+				}
+				continue
+			}
+			errs = append(errs, field.shape.validate(d.F(field.name), fieldPointer)...)
+		}
+	case unstructured.KindList:
+		for i, elem := range d.UnsafeListValue() {
+			errs = append(errs, s.elem.validate(elem, pointer+"/"+strconv.Itoa(i))...)
+		}
+	}
+	return errs
+}
+
+// String describes the shape in a compact, YAML-like notation. For example,
+// `{name: string, port?: number, tags: [string]}`.
+func (s Shape) String() string {
+	switch s.typ {
+	case "":
+		return "any"
+	case unstructured.KindOb:
+		fields := make([]string, len(s.fields))
+		for i, field := range s.fields {
+			name := field.name
+			if field.optional {
+				name += "?"
+			}
+			fields[i] = name + ": " + field.shape.String()
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	case unstructured.KindList:
+		return "[" + s.elem.String() + "]"
+	default:
+		return string(s.typ)
+	}
+}
+
+func describeType(typ unstructured.Kind) string {
+	switch typ {
+	case unstructured.KindOb:
+		return "an object"
+	case unstructured.KindNull:
+		return "null"
+	default:
+		return "a " + string(typ)
+	}
+}
+
+func describeValue(val interface{}) string {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "a list"
+	case string:
+		return strconv.Quote(v)
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
@@ -0,0 +1,13 @@
+package shape_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestShape(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Shape Suite")
+}
@@ -0,0 +1,77 @@
+package shape_test
+
+import (
+	"github.com/totherme/unstructured"
+	"github.com/totherme/unstructured/shape"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Shape", func() {
+	var deployment shape.Shape
+
+	mustParseYAML := func(rawyaml string) unstructured.Data {
+		data, err := unstructured.ParseYAML(rawyaml)
+		Expect(err).NotTo(HaveOccurred())
+		return data
+	}
+
+	BeforeEach(func() {
+		deployment = shape.Object(
+			shape.Field("name", shape.String()),
+			shape.OptionalField("port", shape.Num()),
+			shape.Field("tags", shape.ListOf(shape.String())),
+			shape.OptionalField("jobs", shape.ListOf(shape.Object(
+				shape.Field("name", shape.String()),
+				shape.OptionalField("properties", shape.Any()),
+			))),
+		)
+	})
+
+	It("accepts data with the right shape", func() {
+		Expect(deployment.Validate(mustParseYAML(`
+name: my-deployment
+port: 8080
+tags: [a, b]
+jobs:
+- name: web
+  properties: {anything: [goes]}
+extra: fields are fine
+`))).To(BeEmpty())
+	})
+
+	It("allows optional fields to be missing", func() {
+		Expect(deployment.Validate(mustParseYAML(`{name: my-deployment, tags: []}`))).To(BeEmpty())
+	})
+
+	It("reports every problem with its pointer", func() {
+		errs := deployment.Validate(mustParseYAML(`
+port: "8080"
+tags: [a, 7]
+jobs:
+- properties: null
+- name: [web]
+`))
+		Expect(errs).To(HaveLen(5))
+		Expect(errs[0]).To(MatchError("Missing required field at pointer '/name'"))
+		Expect(errs[1]).To(MatchError(`Expected a number at pointer '/port', but found "8080"`))
+		Expect(errs[2]).To(MatchError("Expected a string at pointer '/tags/1', but found 7"))
+		Expect(errs[3]).To(MatchError("Missing required field at pointer '/jobs/0/name'"))
+		Expect(errs[4]).To(MatchError("Expected a string at pointer '/jobs/1/name', but found a list"))
+	})
+
+	It("escapes field names in pointers", func() {
+		errs := shape.Object(shape.Field("a/b", shape.Null())).Validate(mustParseYAML(`{a/b: 1}`))
+		Expect(errs).To(ConsistOf(MatchError("Expected null at pointer '/a~1b', but found 1")))
+	})
+
+	It("checks the type at the top level", func() {
+		Expect(deployment.Validate(mustParseYAML(`[]`))).To(ConsistOf(MatchError("Expected an object at pointer '', but found a list")))
+		Expect(shape.Bool().Validate(mustParseYAML(`true`))).To(BeEmpty())
+	})
+
+	It("describes itself", func() {
+		Expect(deployment.String()).To(Equal("{name: string, port?: number, tags: [string], jobs?: [{name: string, properties?: any}]}"))
+	})
+})
@@ -1,6 +1,8 @@
 package unstructured_test
 
 import (
+	"github.com/totherme/unstructured"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
@@ -11,3 +13,11 @@ func TestUnstructured(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Unstructured Suite")
 }
+
+// mustParseYAML parses `rawyaml`, failing the current spec if it isn't valid.
+func mustParseYAML(rawyaml string) unstructured.Data {
+	GinkgoHelper()
+	data, err := unstructured.ParseYAML(rawyaml)
+	Expect(err).NotTo(HaveOccurred())
+	return data
+}
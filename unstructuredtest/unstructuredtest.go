@@ -0,0 +1,112 @@
+// Package unstructuredtest provides assertions on unstructured data for tests
+// written with the standard library's testing package. For ginkgo and gomega,
+// see the gunstructured package instead.
+//
+// Each assertion reports a failure with t.Errorf, so the test carries on, and
+// returns true iff the assertion held. Failures describe every pointer at
+// which the data differs from what was wanted, using the same comparison as
+// the gunstructured matchers:
+//
+//	func TestManifest(t *testing.T) {
+//		manifest := generateManifest()
+//		unstructuredtest.AssertPointer(t, manifest, "/instance_groups/name=web/instances", 3)
+//		unstructuredtest.AssertType(t, manifest, unstructured.KindOb)
+//	}
+package unstructuredtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/totherme/unstructured"
+)
+
+// T is the part of testing.TB which the assertions use.
+type T interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// AssertPointer checks that `d` has a value at the json pointer `p`, which is
+// structurally equal to `want`. `want` may be an unstructured.Data, or any go
+// value which can be marshalled to json, in which case it is compared as if it
+// had been marshalled and parsed. So `3` matches the number 3, and
+// `map[string]interface{}{"name": "web"}` matches the object `{name: web}`.
+func AssertPointer(t T, d unstructured.Data, p string, want interface{}) bool {
+	t.Helper()
+	got, err := d.GetByPointer(p)
+	if err != nil {
+		t.Errorf("Couldn't get the value at pointer '%s': %s", p, err)
+		return false
+	}
+	wantData, err := toData(want)
+	if err != nil {
+		t.Errorf("Couldn't compare the value at pointer '%s': %s", p, err)
+		return false
+	}
+	diffs, err := unstructured.Diff(wantData, got)
+	if err != nil {
+		t.Errorf("Couldn't compare the value at pointer '%s': %s", p, err)
+		return false
+	}
+	for i := range diffs {
+		diffs[i].Pointer = p + diffs[i].Pointer
+	}
+	return report(t, diffs)
+}
+
+// AssertEqualData checks that `got` is structurally equal to `want`. Any
+// DiffOptions are passed on to unstructured.Diff, so that some pointers can be
+// ignored, or some lists compared without regard to order.
+func AssertEqualData(t T, got, want unstructured.Data, opts ...unstructured.DiffOption) bool {
+	t.Helper()
+	diffs, err := unstructured.Diff(want, got, opts...)
+	if err != nil {
+		t.Errorf("Couldn't compare the Data: %s", err)
+		return false
+	}
+	return report(t, diffs)
+}
+
+// AssertType checks that `d` is of the Kind `kind`.
+func AssertType(t T, d unstructured.Data, kind unstructured.Kind) bool {
+	t.Helper()
+	if d.Type() == kind {
+		return true
+	}
+	t.Errorf("Expected the Data to be of type %s, but it is of type %s: %s", kind, d.Type(), compactJSON(d.RawValue()))
+	return false
+}
+
+func report(t T, diffs []unstructured.Difference) bool {
+	t.Helper()
+	if len(diffs) == 0 {
+		return true
+	}
+	lines := make([]string, len(diffs))
+	for i, diff := range diffs {
+		lines[i] = "  " + diff.String()
+	}
+	t.Errorf("The Data differs in %d place(s):\n%s", len(diffs), strings.Join(lines, "\n"))
+	return false
+}
+
+func toData(val interface{}) (unstructured.Data, error) {
+	if d, ok := val.(unstructured.Data); ok {
+		return d, nil
+	}
+	encoded, err := json.Marshal(val)
+	if err != nil {
+		return unstructured.Data{}, fmt.Errorf("a %T can't be converted to json: %s", val, err)
+	}
+	return unstructured.ParseJSON(string(encoded))
+}
+
+func compactJSON(val interface{}) string {
+	encoded, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Sprintf("%+v", val)
+	}
+	return string(encoded)
+}
@@ -0,0 +1,13 @@
+package unstructuredtest_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestUnstructuredtest(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Unstructuredtest Suite")
+}
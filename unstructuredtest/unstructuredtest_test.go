@@ -0,0 +1,112 @@
+package unstructuredtest_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/totherme/unstructured"
+	"github.com/totherme/unstructured/unstructuredtest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// fakeT records the failures reported to it.
+type fakeT struct {
+	helperCalls int
+	errors      []string
+}
+
+func (t *fakeT) Helper() {
+	t.helperCalls++
+}
+
+func (t *fakeT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+var _ unstructuredtest.T = testing.TB(nil)
+
+var _ = Describe("unstructuredtest", func() {
+	var (
+		t   *fakeT
+		doc unstructured.Data
+	)
+
+	mustParseYAML := func(rawyaml string) unstructured.Data {
+		data, err := unstructured.ParseYAML(rawyaml)
+		Expect(err).NotTo(HaveOccurred())
+		return data
+	}
+
+	BeforeEach(func() {
+		t = &fakeT{}
+		doc = mustParseYAML(`
+name: my-deployment
+instance_groups:
+- name: web
+  instances: 3
+  azs: [z1, z2]
+`)
+	})
+
+	Describe("AssertPointer", func() {
+		It("passes when the value at the pointer is equal to the wanted one", func() {
+			Expect(unstructuredtest.AssertPointer(t, doc, "/instance_groups/name=web/instances", 3)).To(BeTrue())
+			Expect(unstructuredtest.AssertPointer(t, doc, "/instance_groups/0/azs", []string{"z1", "z2"})).To(BeTrue())
+			Expect(unstructuredtest.AssertPointer(t, doc, "/name", mustParseYAML("my-deployment"))).To(BeTrue())
+			Expect(t.errors).To(BeEmpty())
+			Expect(t.helperCalls).To(BeNumerically(">=", 3))
+		})
+
+		It("reports differences with their full pointers", func() {
+			Expect(unstructuredtest.AssertPointer(t, doc, "/instance_groups/0", map[string]interface{}{
+				"name": "web", "instances": 5, "azs": []string{"z1"},
+			})).To(BeFalse())
+			Expect(t.errors).To(Equal([]string{`The Data differs in 2 place(s):
+  '/instance_groups/0/azs/1': unexpected "z2"
+  '/instance_groups/0/instances': expected 5, got 3`}))
+		})
+
+		It("reports missing pointers", func() {
+			Expect(unstructuredtest.AssertPointer(t, doc, "/missing", 1)).To(BeFalse())
+			Expect(t.errors).To(ConsistOf(HavePrefix("Couldn't get the value at pointer '/missing'")))
+		})
+
+		It("reports wanted values which can't be converted", func() {
+			Expect(unstructuredtest.AssertPointer(t, doc, "/name", func() {})).To(BeFalse())
+			Expect(t.errors).To(ConsistOf(HavePrefix("Couldn't compare the value at pointer '/name': a func() can't be converted to json")))
+		})
+	})
+
+	Describe("AssertEqualData", func() {
+		It("passes for structurally equal data", func() {
+			Expect(unstructuredtest.AssertEqualData(t, doc, mustParseYAML(`
+instance_groups: [{azs: [z1, z2], instances: 3, name: web}]
+name: my-deployment
+`))).To(BeTrue())
+			Expect(t.errors).To(BeEmpty())
+		})
+
+		It("reports every difference", func() {
+			Expect(unstructuredtest.AssertEqualData(t, doc, mustParseYAML(`{name: other, stemcell: default}`))).To(BeFalse())
+			Expect(t.errors).To(Equal([]string{`The Data differs in 3 place(s):
+  '/instance_groups': unexpected [{"azs":["z1","z2"],"instances":3,"name":"web"}]
+  '/name': expected "other", got "my-deployment"
+  '/stemcell': expected "default", but it was missing`}))
+		})
+
+		It("accepts DiffOptions", func() {
+			Expect(unstructuredtest.AssertEqualData(t, doc, mustParseYAML(`{name: other}`),
+				unstructured.IgnoreAt("/name"), unstructured.IgnoreAt("/instance_groups"))).To(BeTrue())
+		})
+	})
+
+	Describe("AssertType", func() {
+		It("checks the Kind of the data", func() {
+			Expect(unstructuredtest.AssertType(t, doc, unstructured.KindOb)).To(BeTrue())
+			Expect(unstructuredtest.AssertType(t, doc.F("name"), unstructured.KindNum)).To(BeFalse())
+			Expect(t.errors).To(Equal([]string{`Expected the Data to be of type number, but it is of type string: "my-deployment"`}))
+		})
+	})
+})
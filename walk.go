@@ -0,0 +1,157 @@
+package unstructured
+
+import "strconv"
+
+// A WalkAction tells Walk what to do after visiting a node.
+type WalkAction struct {
+	op    walkOp
+	value interface{}
+}
+
+type walkOp int
+
+const (
+	walkContinue walkOp = iota
+	walkSkipChildren
+	walkStop
+	walkReplace
+	walkDelete
+)
+
+var (
+	// WalkContinue carries on walking, visiting the children of the current
+	// node if it has any.
+	WalkContinue = WalkAction{op: walkContinue}
+	// WalkSkipChildren carries on walking, but doesn't visit the children of
+	// the current node. In a post-order walk, the children have already been
+	// visited, so this is the same as WalkContinue.
+	WalkSkipChildren = WalkAction{op: walkSkipChildren}
+	// WalkStop stops the walk. Any changes already made are kept.
+	WalkStop = WalkAction{op: walkStop}
+	// WalkDelete removes the current node from its parent object or list, and
+	// carries on walking. Deleting the root of the walk leaves null.
+	WalkDelete = WalkAction{op: walkDelete}
+)
+
+// WalkReplace returns a WalkAction which replaces the current node with
+// `val`, and carries on walking. In a pre-order walk, the new value's
+// children are not visited.
+func WalkReplace(val interface{}) WalkAction {
+	return WalkAction{op: walkReplace, value: val}
+}
+
+// A WalkFunc is called by Walk for each node in a Data tree, with the json
+// pointer of the node.
+type WalkFunc func(pointer string, node Data) WalkAction
+
+// Walk visits every node in this Data tree depth-first, calling `fn` on each
+// node before its children. Object keys are visited in sorted order.
+//
+// The WalkAction returned by `fn` can skip the children of a node, stop the
+// walk early, or replace or delete the node. Objects and lists are changed
+// in place where possible, but since deleting from a list makes a new list,
+// and the root itself may be replaced or deleted, always use the Data struct
+// which Walk returns. The pointers passed to `fn` always refer to the
+// document as it was before the walk changed it.
+func (j Data) Walk(fn WalkFunc) Data {
+	w := &walker{fn: fn}
+	val, _, deleted := w.walk(j.data, nil)
+	if deleted {
+		return Data{}
+	}
+	return Data{data: val}
+}
+
+// WalkPostOrder is like Walk, but calls `fn` on each node after its
+// children, so that `fn` sees any changes it made to them.
+func (j Data) WalkPostOrder(fn WalkFunc) Data {
+	w := &walker{fn: fn, postOrder: true}
+	val, _, deleted := w.walk(j.data, nil)
+	if deleted {
+		return Data{}
+	}
+	return Data{data: val}
+}
+
+type walker struct {
+	fn        WalkFunc
+	postOrder bool
+	stopped   bool
+}
+
+// walk visits `node` and its children, and returns the new value of `node`,
+// whether that is a different value which must be stored in place of the old
+// one, and whether `node` should be deleted instead. Nothing is written to
+// the tree unless `fn` asks for a change, so read-only walks are safe to run
+// concurrently.
+func (w *walker) walk(node interface{}, path []string) (val interface{}, replaced bool, deleted bool) {
+	if w.postOrder {
+		node, replaced = w.walkChildren(node, path)
+		if w.stopped {
+			return node, replaced, false
+		}
+	}
+
+	action := w.fn(JoinPointer(path), Data{data: node})
+	switch action.op {
+	case walkStop:
+		w.stopped = true
+		return node, replaced, false
+	case walkReplace:
+		return action.value, true, false
+	case walkDelete:
+		return nil, false, true
+	case walkSkipChildren:
+		return node, replaced, false
+	}
+
+	if !w.postOrder {
+		node, replaced = w.walkChildren(node, path)
+	}
+	return node, replaced, false
+}
+
+// walkChildren walks the children of `node`, and returns its new value, and
+// whether that is a different value from `node`.
+func (w *walker) walkChildren(node interface{}, path []string) (interface{}, bool) {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedKeys(n) {
+			if w.stopped {
+				break
+			}
+			val, replaced, deleted := w.walk(n[key], appendToken(path, key))
+			if deleted {
+				delete(n, key)
+			} else if replaced {
+				n[key] = val
+			}
+		}
+		return n, false
+	case []interface{}:
+		deleted := map[int]bool{}
+		for i := range n {
+			if w.stopped {
+				break
+			}
+			val, replaced, del := w.walk(n[i], appendToken(path, strconv.Itoa(i)))
+			if del {
+				deleted[i] = true
+			} else if replaced {
+				n[i] = val
+			}
+		}
+		if len(deleted) == 0 {
+			return n, false
+		}
+		kept := make([]interface{}, 0, len(n))
+		for i, val := range n {
+			if !deleted[i] {
+				kept = append(kept, val)
+			}
+		}
+		return kept, true
+	default:
+		return n, false
+	}
+}
@@ -0,0 +1,182 @@
+package unstructured_test
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/totherme/unstructured"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Walk", func() {
+	var doc unstructured.Data
+
+	BeforeEach(func() {
+		doc = mustParseYAML(`
+name: web
+jobs:
+- name: nginx
+  secret: hunter2
+- name: debug
+tags: {env: prod}
+`)
+	})
+
+	It("visits every node depth-first, parents before children", func() {
+		var pointers []string
+		doc.Walk(func(pointer string, node unstructured.Data) unstructured.WalkAction {
+			pointers = append(pointers, pointer)
+			return unstructured.WalkContinue
+		})
+		Expect(pointers).To(Equal([]string{
+			"",
+			"/jobs",
+			"/jobs/0",
+			"/jobs/0/name",
+			"/jobs/0/secret",
+			"/jobs/1",
+			"/jobs/1/name",
+			"/name",
+			"/tags",
+			"/tags/env",
+		}))
+	})
+
+	It("passes each node to the callback", func() {
+		doc.Walk(func(pointer string, node unstructured.Data) unstructured.WalkAction {
+			Expect(doc.GetByPointer(pointer)).To(Equal(node))
+			return unstructured.WalkContinue
+		})
+	})
+
+	It("can skip the children of a node", func() {
+		var pointers []string
+		doc.Walk(func(pointer string, node unstructured.Data) unstructured.WalkAction {
+			pointers = append(pointers, pointer)
+			if node.IsList() {
+				return unstructured.WalkSkipChildren
+			}
+			return unstructured.WalkContinue
+		})
+		Expect(pointers).To(Equal([]string{"", "/jobs", "/name", "/tags", "/tags/env"}))
+	})
+
+	It("can stop early", func() {
+		var pointers []string
+		doc.Walk(func(pointer string, node unstructured.Data) unstructured.WalkAction {
+			pointers = append(pointers, pointer)
+			if pointer == "/jobs/0/name" {
+				return unstructured.WalkStop
+			}
+			return unstructured.WalkContinue
+		})
+		Expect(pointers).To(Equal([]string{"", "/jobs", "/jobs/0", "/jobs/0/name"}))
+	})
+
+	It("can replace nodes", func() {
+		result := doc.Walk(func(pointer string, node unstructured.Data) unstructured.WalkAction {
+			if node.IsString() {
+				return unstructured.WalkReplace(strings.ToUpper(node.UnsafeStringValue()))
+			}
+			return unstructured.WalkContinue
+		})
+		Expect(result).To(Equal(mustParseYAML(`
+name: WEB
+jobs:
+- name: NGINX
+  secret: HUNTER2
+- name: DEBUG
+tags: {env: PROD}
+`)))
+	})
+
+	It("can delete nodes from objects and lists", func() {
+		result := doc.Walk(func(pointer string, node unstructured.Data) unstructured.WalkAction {
+			if strings.HasSuffix(pointer, "/secret") {
+				return unstructured.WalkDelete
+			}
+			if node.IsOb() && node.HasKey("name") && node.F("name").RawValue() == "debug" {
+				return unstructured.WalkDelete
+			}
+			return unstructured.WalkContinue
+		})
+		Expect(result).To(Equal(mustParseYAML(`
+name: web
+jobs:
+- name: nginx
+tags: {env: prod}
+`)))
+	})
+
+	It("can replace or delete the root", func() {
+		Expect(doc.Walk(func(string, unstructured.Data) unstructured.WalkAction {
+			return unstructured.WalkReplace("replaced")
+		})).To(Equal(mustParseYAML("replaced")))
+		Expect(doc.Walk(func(string, unstructured.Data) unstructured.WalkAction {
+			return unstructured.WalkDelete
+		}).IsNull()).To(BeTrue())
+	})
+
+	It("doesn't write to the tree unless asked to", func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				doc.Walk(func(string, unstructured.Data) unstructured.WalkAction {
+					return unstructured.WalkContinue
+				})
+			}()
+		}
+		wg.Wait()
+	})
+
+	Describe("WalkPostOrder", func() {
+		It("visits children before their parents", func() {
+			var pointers []string
+			doc.WalkPostOrder(func(pointer string, node unstructured.Data) unstructured.WalkAction {
+				pointers = append(pointers, pointer)
+				return unstructured.WalkContinue
+			})
+			Expect(pointers).To(Equal([]string{
+				"/jobs/0/name",
+				"/jobs/0/secret",
+				"/jobs/0",
+				"/jobs/1/name",
+				"/jobs/1",
+				"/jobs",
+				"/name",
+				"/tags/env",
+				"/tags",
+				"",
+			}))
+		})
+
+		It("shows parents the changes made to their children", func() {
+			result := doc.WalkPostOrder(func(pointer string, node unstructured.Data) unstructured.WalkAction {
+				if pointer == "/jobs/1/name" {
+					return unstructured.WalkDelete
+				}
+				if node.IsOb() && len(node.UnsafeObValue()) == 0 {
+					return unstructured.WalkDelete
+				}
+				return unstructured.WalkContinue
+			})
+			Expect(result.F("jobs").UnsafeListValue()).To(HaveLen(1))
+		})
+
+		It("can stop early", func() {
+			var pointers []string
+			doc.WalkPostOrder(func(pointer string, node unstructured.Data) unstructured.WalkAction {
+				pointers = append(pointers, pointer)
+				if pointer == "/jobs/0" {
+					return unstructured.WalkStop
+				}
+				return unstructured.WalkContinue
+			})
+			Expect(pointers).To(Equal([]string{"/jobs/0/name", "/jobs/0/secret", "/jobs/0"}))
+		})
+	})
+})